@@ -0,0 +1,27 @@
+// Package app собирает зависимости приложения в контейнер, который
+// передаётся контроллерам при регистрации маршрутов.
+package app
+
+import (
+	"server/repository"
+
+	"gorm.io/gorm"
+)
+
+// New создаёт контейнер с хранилищами поверх подключения к базе данных db.
+func New(db *gorm.DB) *Container {
+	return &Container{
+		Beers:  repository.NewGormBeers(db),
+		Snacks: repository.NewGormSnacks(db),
+		Audit:  repository.NewGormAudit(db),
+	}
+}
+
+// NewInMemory создаёт контейнер с хранилищами в памяти, не требующими базы данных.
+func NewInMemory() *Container {
+	return &Container{
+		Beers:  repository.NewMemoryBeers(),
+		Snacks: repository.NewMemorySnacks(),
+		Audit:  repository.NewMemoryAudit(),
+	}
+}
@@ -0,0 +1,12 @@
+// Package app собирает зависимости приложения в контейнер, который
+// передаётся контроллерам при регистрации маршрутов.
+package app
+
+import "server/repository"
+
+// Container содержит зависимости, используемые контроллерами.
+type Container struct {
+	Beers  repository.BeerRepository  // Хранилище пива
+	Snacks repository.SnackRepository // Хранилище закусок
+	Audit  repository.AuditRepository // Журнал изменений
+}
@@ -0,0 +1,94 @@
+// Package audit ведёт журнал изменений данных: кто, когда и что изменил.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"server/auth"
+	"server/models"
+	"server/request"
+
+	"gorm.io/gorm"
+)
+
+// Действия, фиксируемые в журнале.
+const (
+	Create  = "create"
+	Update  = "update"
+	Delete  = "delete"
+	Restore = "restore" // восстановление из корзины
+	Purge   = "purge"   // окончательное удаление
+)
+
+// ignoredFields — служебные поля, изменения которых не записываются в журнал.
+var ignoredFields = map[string]bool{"UpdatedAt": true}
+
+// change — изменение одного поля.
+type change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Record записывает в журнал действие action над сущностью entity с идентификатором id.
+// before и after — состояния сущности до и после изменения (nil при создании и удалении
+// соответственно). Субъект и идентификатор запроса берутся из ctx.
+// Запись следует выполнять через tx той же транзакции, что и само изменение.
+func Record(ctx context.Context, tx *gorm.DB, action, entity string, id uint, before, after any) error {
+	changes, err := Diff(before, after)
+	if err != nil {
+		return err
+	}
+
+	entry := &models.AuditEntry{
+		Action:    action,
+		Entity:    entity,
+		EntityID:  id,
+		Changes:   changes,
+		RequestID: request.IDFromContext(ctx),
+	}
+	if p := auth.PrincipalFromContext(ctx); p != nil {
+		entry.ActorKind, entry.ActorID, entry.ActorName = p.Kind, p.ID, p.Name
+	}
+	return models.CreateAuditEntry(tx, entry)
+}
+
+// Diff сравнивает JSON-представления before и after и возвращает изменённые поля
+// в виде {"поле": {"before": ..., "after": ...}}.
+func Diff(before, after any) (json.RawMessage, error) {
+	b, err := toMap(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]change{}
+	for key, val := range b {
+		if !ignoredFields[key] && !reflect.DeepEqual(val, a[key]) {
+			changes[key] = change{Before: val, After: a[key]}
+		}
+	}
+	for key, val := range a {
+		if _, ok := b[key]; !ok && val != nil && !ignoredFields[key] {
+			changes[key] = change{After: val}
+		}
+	}
+	return json.Marshal(changes)
+}
+
+// toMap преобразует значение в map через его JSON-представление.
+func toMap(v any) (map[string]any, error) {
+	result := map[string]any{}
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return result, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
@@ -0,0 +1,121 @@
+// Package auth реализует аутентификацию клиентов API.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"server/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix — префикс, по которому ключи API отличаются от других токенов.
+const APIKeyPrefix = "bsk_"
+
+// touchInterval — минимальный интервал между обновлениями времени последнего использования ключа.
+const touchInterval = time.Minute
+
+// APIKeyAuthenticator проверяет bearer-токены как ключи API, хранящиеся в базе данных.
+type APIKeyAuthenticator struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyAuthenticator создаёт аутентификатор ключей API.
+func NewAPIKeyAuthenticator(db *gorm.DB) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{db: db}
+}
+
+// Authenticate ищет неотозванный ключ по хешу токена и отмечает его использование.
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	if !strings.HasPrefix(token, APIKeyPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	db := a.db.WithContext(ctx)
+	key, err := models.GetActiveAPIKeyByHash(db, HashAPIKey(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	touchAPIKey(db, key)
+	return &Principal{Kind: "api_key", ID: key.ID, Name: key.Name}, nil
+}
+
+// touchAPIKey отмечает использование ключа не чаще раза в touchInterval.
+func touchAPIKey(db *gorm.DB, key *models.APIKey) {
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= touchInterval {
+		if err := models.TouchAPIKey(db, key.ID, now); err != nil {
+			log.Printf("auth: failed to update api key %d last use: %v", key.ID, err)
+		}
+	}
+}
+
+// IssueAPIKey создаёт новый ключ API с названием name.
+// Возвращает сохранённую запись и сам ключ, который больше нигде не хранится.
+func IssueAPIKey(db *gorm.DB, name string) (*models.APIKey, string, error) {
+	token, err := randomToken(APIKeyPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &models.APIKey{
+		Name:   name,
+		Prefix: token[:len(APIKeyPrefix)+8],
+		Hash:   HashAPIKey(token),
+	}
+	if err := models.CreateAPIKey(db, key); err != nil {
+		return nil, "", err
+	}
+	return key, token, nil
+}
+
+// IssueSigningKey создаёт ключ подписи запросов с названием name.
+// Возвращает сохранённую запись и секрет подписи. Bearer-токен такого ключа
+// никому не выдаётся, так что аутентифицироваться им можно только подписью.
+func IssueSigningKey(db *gorm.DB, name string) (*models.APIKey, string, error) {
+	token, err := randomToken(APIKeyPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomToken("")
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &models.APIKey{
+		Name:   name,
+		Prefix: token[:len(APIKeyPrefix)+8],
+		Hash:   HashAPIKey(token),
+		Secret: secret,
+	}
+	if err := models.CreateAPIKey(db, key); err != nil {
+		return nil, "", err
+	}
+	return key, secret, nil
+}
+
+// randomToken возвращает 32 случайных байта в base64url с префиксом prefix.
+func randomToken(prefix string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashAPIKey возвращает SHA-256 хеш ключа в шестнадцатеричном виде.
+func HashAPIKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,136 @@
+// Package auth реализует аутентификацию клиентов API.
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"server/models"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// Типы JWT, выпускаемых сервером (claim typ).
+const (
+	accessTokenType  = "access"
+	refreshTokenType = "refresh"
+)
+
+// TokenConfig задаёт параметры выпуска и проверки JWT.
+// Если задан Ed25519Key, токены подписываются EdDSA, иначе — HMAC-SHA256.
+// Проверяются токены, подписанные любым из настроенных ключей.
+type TokenConfig struct {
+	Issuer     string             // Издатель токенов (claim iss)
+	AccessTTL  time.Duration      // Время жизни access-токена
+	RefreshTTL time.Duration      // Время жизни refresh-токена
+	HMACSecret []byte             // Секрет для HS256
+	Ed25519Key ed25519.PrivateKey // Закрытый ключ для EdDSA
+}
+
+// Claims — содержимое JWT, выпускаемых сервером.
+type Claims struct {
+	jwt.RegisteredClaims
+	Type   string `json:"typ"`           // Тип токена: access или refresh
+	Family string `json:"fam,omitempty"` // Цепочка ротации refresh-токена
+}
+
+// sign подписывает claims ключом, выбранным в конфигурации.
+func (c TokenConfig) sign(claims Claims) (string, error) {
+	if c.Ed25519Key != nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		token.Header["kid"] = "ed25519"
+		return token.SignedString(c.Ed25519Key)
+	}
+	if len(c.HMACSecret) == 0 {
+		return "", errors.New("no JWT signing key configured")
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = "hmac"
+	return token.SignedString(c.HMACSecret)
+}
+
+// parse проверяет подпись, издателя, срок действия и тип токена.
+func (c TokenConfig) parse(tokenString, tokenType string) (*Claims, error) {
+	var methods []string
+	if c.Ed25519Key != nil {
+		methods = append(methods, jwt.SigningMethodEdDSA.Alg())
+	}
+	if len(c.HMACSecret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		switch token.Method.Alg() {
+		case jwt.SigningMethodEdDSA.Alg():
+			return c.Ed25519Key.Public(), nil
+		case jwt.SigningMethodHS256.Alg():
+			return c.HMACSecret, nil
+		}
+		return nil, ErrInvalidToken
+	}, jwt.WithValidMethods(methods), jwt.WithIssuer(c.Issuer), jwt.WithExpirationRequired())
+	if err != nil || claims.Type != tokenType {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// newClaims создаёт claims токена типа tokenType для пользователя userID со временем жизни ttl.
+func (c TokenConfig) newClaims(userID uint, tokenType string, ttl time.Duration) Claims {
+	now := time.Now()
+	return Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    c.Issuer,
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			ID:        randomID(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Type: tokenType,
+	}
+}
+
+// JWTAuthenticator проверяет bearer-токены как access-токены пользователей.
+type JWTAuthenticator struct {
+	tokens *TokenService
+}
+
+// NewJWTAuthenticator создаёт аутентификатор access-токенов, выпущенных сервисом tokens.
+func NewJWTAuthenticator(tokens *TokenService) *JWTAuthenticator {
+	return &JWTAuthenticator{tokens: tokens}
+}
+
+// Authenticate проверяет access-токен и возвращает пользователя из claim sub.
+// Токен удалённого пользователя отклоняется, даже если срок его действия не истёк.
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	claims, err := a.tokens.config.parse(token, accessTokenType)
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if _, err := models.GetUserByID(a.tokens.db.WithContext(ctx), uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	return &Principal{Kind: "user", ID: uint(id), Name: claims.Subject}, nil
+}
+
+// randomID генерирует случайный идентификатор для claims jti и fam.
+func randomID() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
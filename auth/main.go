@@ -0,0 +1,64 @@
+// Package auth реализует аутентификацию клиентов API.
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"server/request"
+	"server/router"
+	"server/types"
+)
+
+const principalKey contextKey = "principal"
+
+// WithPrincipal возвращает копию контекста с аутентифицированным клиентом p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// PrincipalFromContext возвращает аутентифицированного клиента из контекста,
+// или nil, если запрос анонимный.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey).(*Principal)
+	return p
+}
+
+// CurrentPrincipal возвращает аутентифицированного клиента текущего запроса, или nil.
+func CurrentPrincipal(r *request.Request) *Principal {
+	return PrincipalFromContext(r.Context())
+}
+
+// Middleware проверяет bearer-токен из заголовка Authorization по очереди каждым
+// аутентификатором и сохраняет найденного клиента в контексте запроса.
+// Запросы без токена пропускаются как анонимные, с недействительным токеном — отклоняются с кодом 401.
+func Middleware(authenticators ...Authenticator) types.Middleware {
+	return func(next types.HandlerFunc) types.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			req := request.InitRequest(r)
+			token := req.BearerToken()
+			if token == "" {
+				next(w, r)
+				return
+			}
+
+			for _, authenticator := range authenticators {
+				p, err := authenticator.Authenticate(r.Context(), token)
+				if errors.Is(err, ErrInvalidToken) {
+					continue
+				}
+				if err != nil {
+					log.Printf("auth: %v", err)
+					router.WriteJson(w, r, types.JsonResponse{Status: "error", Message: req.T("auth.failed"), Code: http.StatusInternalServerError})
+					return
+				}
+				next(w, r.WithContext(WithPrincipal(r.Context(), p)))
+				return
+			}
+
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			router.WriteJson(w, r, types.JsonResponse{Status: "error", Message: req.T("auth.invalid_token"), Code: http.StatusUnauthorized})
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Package auth реализует аутентификацию клиентов API.
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NonceStore запоминает использованные одноразовые значения подписей.
+// Для нескольких экземпляров сервера нужна реализация поверх общего хранилища.
+type NonceStore interface {
+	// Use отмечает значение key использованным на время ttl.
+	// Возвращает false, если оно уже было использовано.
+	Use(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// MemoryNonceStore хранит одноразовые значения в памяти процесса.
+type MemoryNonceStore struct {
+	mu        sync.Mutex
+	seen      map[string]time.Time // значение -> время, после которого его можно забыть
+	lastSweep time.Time
+}
+
+// NewMemoryNonceStore создаёт хранилище одноразовых значений в памяти.
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{seen: make(map[string]time.Time), lastSweep: time.Now()}
+}
+
+// Use отмечает значение key использованным на время ttl.
+func (s *MemoryNonceStore) Use(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= time.Minute {
+		for k, expires := range s.seen {
+			if now.After(expires) {
+				delete(s.seen, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if expires, ok := s.seen[key]; ok && now.Before(expires) {
+		return false, nil
+	}
+	s.seen[key] = now.Add(ttl)
+	return true, nil
+}
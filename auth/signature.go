@@ -0,0 +1,137 @@
+// Package auth реализует аутентификацию клиентов API.
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"server/client"
+	"server/models"
+	"server/request"
+	"server/router"
+	"server/types"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxSignedBody — максимальный размер тела подписанного запроса.
+const maxSignedBody = 10 << 20
+
+// ErrInvalidSignature возвращается, если подпись запроса недействительна,
+// устарела или уже использовалась.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// SignatureVerifier проверяет подписи запросов HMAC-SHA256, сделанные client.Signer.
+type SignatureVerifier struct {
+	db      *gorm.DB
+	nonces  NonceStore
+	maxSkew time.Duration
+}
+
+// NewSignatureVerifier создаёт проверку подписей. Подпись принимается, если её время
+// отличается от времени сервера не больше чем на maxSkew; одноразовые значения
+// запоминаются в nonces на удвоенное maxSkew.
+func NewSignatureVerifier(db *gorm.DB, nonces NonceStore, maxSkew time.Duration) *SignatureVerifier {
+	return &SignatureVerifier{db: db, nonces: nonces, maxSkew: maxSkew}
+}
+
+// Middleware проверяет подпись запросов с заголовком client.HeaderSignature и сохраняет
+// владельца ключа в контексте запроса. Запросы без подписи пропускаются без изменений,
+// с недействительной подписью — отклоняются с кодом 401. Подписанный запрос, клиент которого
+// уже аутентифицирован предыдущим middleware (например, по bearer-токену), отклоняется
+// с кодом 400: иначе один клиент незаметно подменял бы другого.
+func (v *SignatureVerifier) Middleware() types.Middleware {
+	return func(next types.HandlerFunc) types.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(client.HeaderSignature) == "" {
+				next(w, r)
+				return
+			}
+
+			req := request.InitRequest(r)
+			if PrincipalFromContext(r.Context()) != nil {
+				router.WriteJson(w, r, types.JsonResponse{Status: "error", Message: req.T("auth.multiple_credentials"), Code: http.StatusBadRequest})
+				return
+			}
+
+			p, err := v.verify(r)
+			if err != nil {
+				if errors.Is(err, ErrInvalidSignature) {
+					router.WriteJson(w, r, types.JsonResponse{Status: "error", Message: req.T("auth.invalid_signature"), Code: http.StatusUnauthorized})
+					return
+				}
+				log.Printf("auth: %v", err)
+				router.WriteJson(w, r, types.JsonResponse{Status: "error", Message: req.T("auth.failed"), Code: http.StatusInternalServerError})
+				return
+			}
+			next(w, r.WithContext(WithPrincipal(r.Context(), p)))
+		}
+	}
+}
+
+// verify проверяет время, хеш тела, подпись и одноразовое значение запроса.
+func (v *SignatureVerifier) verify(r *http.Request) (*Principal, error) {
+	keyID := r.Header.Get(client.HeaderKey)
+	timestamp := r.Header.Get(client.HeaderTimestamp)
+	nonce := r.Header.Get(client.HeaderNonce)
+	bodyHash := r.Header.Get(client.HeaderBodyHash)
+	signature, err := hex.DecodeString(r.Header.Get(client.HeaderSignature))
+	if err != nil || keyID == "" || nonce == "" {
+		return nil, ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	if skew := time.Since(time.Unix(unix, 0)); skew > v.maxSkew || skew < -v.maxSkew {
+		return nil, ErrInvalidSignature
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBody+1))
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	if len(body) > maxSignedBody || client.HashBody(body) != bodyHash {
+		return nil, ErrInvalidSignature
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	id, err := strconv.ParseUint(keyID, 10, 0)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	db := v.db.WithContext(r.Context())
+	key, err := models.GetActiveSigningKey(db, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidSignature
+		}
+		return nil, err
+	}
+
+	expected, _ := hex.DecodeString(client.Signature([]byte(key.Secret),
+		client.StringToSign(r.Method, client.RequestTarget(r), timestamp, nonce, bodyHash)))
+	if !hmac.Equal(signature, expected) {
+		return nil, ErrInvalidSignature
+	}
+
+	// Одноразовое значение проверяется последним, чтобы чужие запросы с неверной
+	// подписью не могли занять его заранее
+	fresh, err := v.nonces.Use(r.Context(), keyID+":"+nonce, 2*v.maxSkew)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		return nil, ErrInvalidSignature
+	}
+
+	touchAPIKey(db, key)
+	return &Principal{Kind: "api_key", ID: key.ID, Name: key.Name}, nil
+}
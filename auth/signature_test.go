@@ -0,0 +1,235 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"server/client"
+	"server/models"
+	"server/types"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// signatureMaxSkew — допустимое расхождение времени подписи в тестах.
+const signatureMaxSkew = 5 * time.Minute
+
+// signatureTest — проверка подписей с ключом подписи и ключом bearer в отдельной базе.
+type signatureTest struct {
+	db       *gorm.DB
+	handler  types.HandlerFunc
+	key      *models.APIKey
+	signer   *client.Signer
+	bearerID uint
+}
+
+// newSignatureTest создаёт проверку подписей, за которой стоит обработчик,
+// отвечающий идентификатором аутентифицированного ключа.
+func newSignatureTest(t *testing.T) *signatureTest {
+	t.Helper()
+	db := newTestDB(t, &models.APIKey{})
+	key, secret, err := IssueSigningKey(db, "signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bearer, _, err := IssueAPIKey(db, "bearer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifier := NewSignatureVerifier(db, NewMemoryNonceStore(), signatureMaxSkew)
+	handler := verifier.Middleware()(func(w http.ResponseWriter, r *http.Request) {
+		if p := PrincipalFromContext(r.Context()); p != nil {
+			body, _ := io.ReadAll(r.Body)
+			io.WriteString(w, p.Kind+":"+strconv.Itoa(int(p.ID))+":"+string(body))
+		}
+	})
+	return &signatureTest{
+		db:       db,
+		handler:  handler,
+		key:      key,
+		signer:   &client.Signer{KeyID: strconv.Itoa(int(key.ID)), Secret: []byte(secret)},
+		bearerID: bearer.ID,
+	}
+}
+
+// request создаёт подписанный запрос method к target с телом body.
+func (s *signatureTest) request(t *testing.T, method, target, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if err := s.signer.Sign(req); err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+// serve выполняет запрос и возвращает ответ.
+func (s *signatureTest) serve(req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.handler(w, req)
+	return w
+}
+
+// expectAccepted проверяет, что запрос аутентифицирован ключом подписи и тело передано обработчику.
+func (s *signatureTest) expectAccepted(t *testing.T, req *http.Request, body string) {
+	t.Helper()
+	w := s.serve(req)
+	if want := "api_key:" + strconv.Itoa(int(s.key.ID)) + ":" + body; w.Code != http.StatusOK || w.Body.String() != want {
+		t.Fatalf("expected %q, got %d %s", want, w.Code, w.Body)
+	}
+}
+
+// expectRejected проверяет, что запрос отклонён с кодом code.
+func expectRejected(t *testing.T, w *httptest.ResponseRecorder, code int) {
+	t.Helper()
+	if w.Code != code {
+		t.Fatalf("expected status %d, got %d: %s", code, w.Code, w.Body)
+	}
+}
+
+func TestSignatureValid(t *testing.T) {
+	s := newSignatureTest(t)
+	s.expectAccepted(t, s.request(t, http.MethodPost, "/beer/", `{"Name":"Porter"}`), `{"Name":"Porter"}`)
+	s.expectAccepted(t, s.request(t, http.MethodGet, "/beers/", ""), "")
+
+	w := s.serve(httptest.NewRequest(http.MethodGet, "/beers/", nil))
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Fatalf("unsigned request should pass anonymously, got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestSignatureClockSkew(t *testing.T) {
+	s := newSignatureTest(t)
+	for _, offset := range []time.Duration{-signatureMaxSkew - time.Minute, signatureMaxSkew + time.Minute} {
+		s.signer.Now = func() time.Time { return time.Now().Add(offset) }
+		expectRejected(t, s.serve(s.request(t, http.MethodGet, "/beers/", "")), http.StatusUnauthorized)
+	}
+
+	s.signer.Now = func() time.Time { return time.Now().Add(-signatureMaxSkew + time.Minute) }
+	s.expectAccepted(t, s.request(t, http.MethodGet, "/beers/", ""), "")
+}
+
+func TestSignatureBodyHash(t *testing.T) {
+	s := newSignatureTest(t)
+
+	// Подменённое тело не совпадает с подписанным хешем
+	req := s.request(t, http.MethodPost, "/beer/", `{"Name":"Porter"}`)
+	req.Body = io.NopCloser(strings.NewReader(`{"Name":"Stout"}`))
+	expectRejected(t, s.serve(req), http.StatusUnauthorized)
+
+	// Хеш подменённого тела не совпадает с подписью
+	req = s.request(t, http.MethodPost, "/beer/", `{"Name":"Porter"}`)
+	req.Body = io.NopCloser(strings.NewReader(`{"Name":"Stout"}`))
+	req.Header.Set(client.HeaderBodyHash, client.HashBody([]byte(`{"Name":"Stout"}`)))
+	expectRejected(t, s.serve(req), http.StatusUnauthorized)
+}
+
+func TestSignatureNonceReplay(t *testing.T) {
+	s := newSignatureTest(t)
+	req := s.request(t, http.MethodPost, "/beer/", `{"Name":"Porter"}`)
+	replay := httptest.NewRequest(http.MethodPost, "/beer/", strings.NewReader(`{"Name":"Porter"}`))
+	replay.Header = req.Header.Clone()
+
+	s.expectAccepted(t, req, `{"Name":"Porter"}`)
+	expectRejected(t, s.serve(replay), http.StatusUnauthorized)
+}
+
+func TestSignatureNonceNotConsumedByForgery(t *testing.T) {
+	s := newSignatureTest(t)
+	req := s.request(t, http.MethodGet, "/beers/", "")
+	forged := httptest.NewRequest(http.MethodGet, "/beers/", nil)
+	forged.Header = req.Header.Clone()
+	forged.Header.Set(client.HeaderSignature, strings.Repeat("0", 64))
+
+	expectRejected(t, s.serve(forged), http.StatusUnauthorized)
+	s.expectAccepted(t, req, "")
+}
+
+func TestSignatureKey(t *testing.T) {
+	s := newSignatureTest(t)
+	signer := *s.signer
+
+	// Неверный секрет
+	s.signer.Secret = []byte("wrong")
+	expectRejected(t, s.serve(s.request(t, http.MethodGet, "/beers/", "")), http.StatusUnauthorized)
+
+	// Неизвестный ключ и ключ bearer без секрета подписи
+	for _, keyID := range []string{"999", strconv.Itoa(int(s.bearerID)), "x", ""} {
+		*s.signer = signer
+		s.signer.KeyID = keyID
+		expectRejected(t, s.serve(s.request(t, http.MethodGet, "/beers/", "")), http.StatusUnauthorized)
+	}
+
+	// Отозванный ключ
+	*s.signer = signer
+	if err := models.RevokeAPIKey(s.db, s.key.ID); err != nil {
+		t.Fatal(err)
+	}
+	expectRejected(t, s.serve(s.request(t, http.MethodGet, "/beers/", "")), http.StatusUnauthorized)
+}
+
+func TestSignatureRequestTarget(t *testing.T) {
+	s := newSignatureTest(t)
+	s.expectAccepted(t, s.request(t, http.MethodGet, "/beers/?q=dark%20ale&limit=5", ""), "")
+	s.expectAccepted(t, s.request(t, http.MethodGet, "/beer/caf%C3%A9", ""), "")
+
+	tampered := []struct {
+		name   string
+		signed string
+		sent   string
+	}{
+		{"changed value", "/beers/?q=ale&limit=5", "/beers/?q=ale&limit=500"},
+		{"reordered parameters", "/beers/?q=ale&limit=5", "/beers/?limit=5&q=ale"},
+		{"added parameter", "/beers/?q=ale", "/beers/?q=ale&offset=1"},
+		{"dropped query", "/beers/?q=ale", "/beers/"},
+		{"different escaping", "/beers/?q=dark%20ale", "/beers/?q=dark+ale"},
+		{"changed path", "/beer/1", "/beer/2"},
+	}
+	for _, tt := range tampered {
+		t.Run(tt.name, func(t *testing.T) {
+			signed := s.request(t, http.MethodGet, tt.signed, "")
+			req := httptest.NewRequest(http.MethodGet, tt.sent, nil)
+			req.Header = signed.Header.Clone()
+			expectRejected(t, s.serve(req), http.StatusUnauthorized)
+		})
+	}
+
+	// Метод входит в подпись
+	signed := s.request(t, http.MethodGet, "/beer/1", "")
+	req := httptest.NewRequest(http.MethodDelete, "/beer/1", nil)
+	req.Header = signed.Header.Clone()
+	expectRejected(t, s.serve(req), http.StatusUnauthorized)
+}
+
+// bearerAuthenticator принимает любой токен как ключ API с идентификатором, равным своему значению.
+type bearerAuthenticator uint
+
+// Authenticate возвращает ключ API с идентификатором a.
+func (a bearerAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	return &Principal{Kind: "api_key", ID: uint(a)}, nil
+}
+
+func TestSignatureWithBearerToken(t *testing.T) {
+	s := newSignatureTest(t)
+	handler := Middleware(bearerAuthenticator(s.bearerID))(s.handler)
+
+	req := s.request(t, http.MethodGet, "/beers/", "")
+	req.Header.Set("Authorization", "Bearer token")
+	w := httptest.NewRecorder()
+	handler(w, req)
+	expectRejected(t, w, http.StatusBadRequest)
+
+	// Без подписи bearer-токен по-прежнему принимается
+	req = httptest.NewRequest(http.MethodGet, "/beers/", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	w = httptest.NewRecorder()
+	handler(w, req)
+	if want := "api_key:" + strconv.Itoa(int(s.bearerID)) + ":"; w.Body.String() != want {
+		t.Fatalf("expected %q, got %d %s", want, w.Code, w.Body)
+	}
+}
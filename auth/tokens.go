@@ -0,0 +1,179 @@
+// Package auth реализует аутентификацию клиентов API.
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"server/models"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrTokenReused возвращается при повторном предъявлении уже обменянного refresh-токена.
+// В этом случае вся цепочка ротации отзывается.
+var ErrTokenReused = errors.New("refresh token reuse detected")
+
+// ErrInvalidCredentials возвращается при неверном логине или пароле.
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// Tokens — сервис выпуска токенов, настроенный через InitTokens.
+var Tokens *TokenService
+
+// TokenPair — пара токенов, выдаваемая при входе и обновлении.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // Время жизни access-токена в секундах
+}
+
+// TokenService выпускает, обновляет и отзывает JWT пользователей.
+type TokenService struct {
+	db     *gorm.DB
+	config TokenConfig
+}
+
+// InitTokens создаёт сервис токенов с конфигурацией config и сохраняет его в Tokens.
+func InitTokens(db *gorm.DB, config TokenConfig) error {
+	if config.Ed25519Key == nil && len(config.HMACSecret) == 0 {
+		return errors.New("no JWT signing key configured")
+	}
+	if config.AccessTTL <= 0 {
+		config.AccessTTL = 15 * time.Minute
+	}
+	if config.RefreshTTL <= 0 {
+		config.RefreshTTL = 30 * 24 * time.Hour
+	}
+	Tokens = &TokenService{db: db, config: config}
+	return nil
+}
+
+// Login проверяет логин и пароль и выпускает новую пару токенов.
+// Хеш пароля в устаревшем формате пересчитывается.
+func (s *TokenService) Login(ctx context.Context, email, password string) (*TokenPair, error) {
+	db := s.db.WithContext(ctx)
+	user, err := models.GetUserByEmail(db, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Выравниваем время ответа с проверкой пароля существующего пользователя
+			HashPassword(password)
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	ok, err := CheckPassword(user.PasswordHash, password)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	if NeedsRehash(user.PasswordHash) {
+		if hash, err := HashPassword(password); err == nil {
+			if err := models.UpdateUserPassword(db, user.ID, hash); err != nil {
+				log.Printf("auth: failed to rehash password of user %d: %v", user.ID, err)
+			}
+		}
+	}
+
+	return s.issue(ctx, user.ID, randomID())
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов той же цепочки.
+// Повторное использование токена отзывает всю цепочку и возвращает ErrTokenReused,
+// токен удалённого пользователя отклоняется с ErrInvalidToken.
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	claims, err := s.config.parse(refreshToken, refreshTokenType)
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.db.WithContext(ctx)
+	used, err := models.UseRefreshToken(db, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, s.rejectRefresh(db, claims)
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if _, err := models.GetUserByID(db, uint(userID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	return s.issue(ctx, uint(userID), claims.Family)
+}
+
+// rejectRefresh определяет, почему refresh-токен не удалось обменять.
+// Повторное предъявление уже обменянного токена отзывает всю цепочку и возвращает ErrTokenReused;
+// неизвестный, отозванный или истёкший токен — ErrInvalidToken без отзыва цепочки.
+func (s *TokenService) rejectRefresh(db *gorm.DB, claims *Claims) error {
+	token, err := models.GetRefreshTokenByJTI(db, claims.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+	if token.UsedAt == nil {
+		return ErrInvalidToken
+	}
+
+	if err := models.RevokeRefreshTokenFamily(db, token.FamilyID); err != nil {
+		return err
+	}
+	log.Printf("auth: refresh token reuse detected, family %s of user %d revoked", token.FamilyID, token.UserID)
+	return ErrTokenReused
+}
+
+// Revoke отзывает цепочку ротации, к которой принадлежит refresh-токен.
+func (s *TokenService) Revoke(ctx context.Context, refreshToken string) error {
+	claims, err := s.config.parse(refreshToken, refreshTokenType)
+	if err != nil {
+		return err
+	}
+	return models.RevokeRefreshTokenFamily(s.db.WithContext(ctx), claims.Family)
+}
+
+// issue выпускает access- и refresh-токены пользователя в цепочке family.
+func (s *TokenService) issue(ctx context.Context, userID uint, family string) (*TokenPair, error) {
+	access, err := s.config.sign(s.config.newClaims(userID, accessTokenType, s.config.AccessTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	refreshClaims := s.config.newClaims(userID, refreshTokenType, s.config.RefreshTTL)
+	refreshClaims.Family = family
+	refresh, err := s.config.sign(refreshClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  family,
+		JTI:       refreshClaims.ID,
+		ExpiresAt: refreshClaims.ExpiresAt.Time,
+	}
+	if err := models.CreateRefreshToken(s.db.WithContext(ctx), record); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.config.AccessTTL.Seconds()),
+	}, nil
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"server/models"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// newTokenService создаёт сервис токенов с отдельной базой SQLite в памяти и одним пользователем.
+func newTokenService(t *testing.T) (*TokenService, *gorm.DB) {
+	t.Helper()
+	db := newTestDB(t, &models.User{}, &models.RefreshToken{})
+	hash, err := HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := models.CreateUser(db, &models.User{Email: "user@example.com", PasswordHash: hash}); err != nil {
+		t.Fatal(err)
+	}
+
+	service := &TokenService{db: db, config: TokenConfig{
+		Issuer:     "test",
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+		HMACSecret: []byte("0123456789abcdef0123456789abcdef"),
+	}}
+	return service, db
+}
+
+// revokedTokens возвращает число отозванных refresh-токенов.
+func revokedTokens(t *testing.T, db *gorm.DB) int64 {
+	t.Helper()
+	var count int64
+	if err := db.Model(&models.RefreshToken{}).Where("revoked_at IS NOT NULL").Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestRefreshRotation(t *testing.T) {
+	service, db := newTokenService(t)
+	ctx := context.Background()
+
+	pair, err := service.Login(ctx, "user@example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if pair, err = service.Refresh(ctx, pair.RefreshToken); err != nil {
+			t.Fatalf("rotation %d: %v", i, err)
+		}
+	}
+	if n := revokedTokens(t, db); n != 0 {
+		t.Fatalf("normal rotation revoked %d tokens", n)
+	}
+	if _, err := service.config.parse(pair.AccessToken, accessTokenType); err != nil {
+		t.Fatalf("rotated access token is invalid: %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	service, db := newTokenService(t)
+	ctx := context.Background()
+
+	first, err := service.Login(ctx, "user@example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := service.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Независимая сессия того же пользователя не должна пострадать
+	other, err := service.Login(ctx, "user@example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrTokenReused) {
+		t.Fatalf("expected ErrTokenReused, got %v", err)
+	}
+	if n := revokedTokens(t, db); n != 2 {
+		t.Fatalf("expected 2 revoked tokens in the family, got %d", n)
+	}
+	if _, err := service.Refresh(ctx, second.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected the latest token of the family to be rejected, got %v", err)
+	}
+	if _, err := service.Refresh(ctx, other.RefreshToken); err != nil {
+		t.Fatalf("token of another family was revoked: %v", err)
+	}
+}
+
+func TestRefreshInvalidToken(t *testing.T) {
+	service, db := newTokenService(t)
+	ctx := context.Background()
+
+	pair, err := service.Login(ctx, "user@example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Токен, не сохранённый в базе
+	unknown := service.config.newClaims(1, refreshTokenType, time.Hour)
+	unknown.Family = "unknown"
+	token, err := service.config.sign(unknown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Refresh(ctx, token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for unknown token, got %v", err)
+	}
+
+	// Истёкшая запись не отзывает цепочку
+	if err := db.Model(&models.RefreshToken{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for expired token, got %v", err)
+	}
+	if n := revokedTokens(t, db); n != 0 {
+		t.Fatalf("invalid token revoked %d tokens", n)
+	}
+
+	if _, err := service.Refresh(ctx, pair.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for access token, got %v", err)
+	}
+}
+
+func TestRefreshDeletedUser(t *testing.T) {
+	service, db := newTokenService(t)
+	ctx := context.Background()
+
+	pair, err := service.Login(ctx, "user@example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&models.User{}, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for deleted user, got %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+// Package auth реализует аутентификацию клиентов API.
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+// contextKey используется для хранения значений в контексте HTTP-запроса.
+type contextKey string
+
+// ErrInvalidToken возвращается аутентификатором, если токен ему не принадлежит
+// или недействителен.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Principal описывает аутентифицированного клиента API.
+type Principal struct {
+	Kind string // Способ аутентификации: "api_key", "user" или "system" для фоновых задач сервера
+	ID   uint   // Идентификатор ключа или пользователя
+	Name string // Отображаемое имя
+}
+
+// SystemPrincipal возвращает субъекта фоновой задачи сервера name, от имени
+// которой изменения записываются в журнал без участия клиента.
+func SystemPrincipal(name string) *Principal {
+	return &Principal{Kind: "system", Name: name}
+}
+
+// Authenticator проверяет bearer-токен и возвращает соответствующего ему клиента.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Principal, error)
+}
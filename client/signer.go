@@ -0,0 +1,113 @@
+// Package client содержит вспомогательные средства для клиентов API на Go.
+package client
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Заголовки подписи запроса.
+const (
+	HeaderKey       = "X-Signature-Key"       // Идентификатор ключа подписи
+	HeaderTimestamp = "X-Signature-Timestamp" // Время подписи, Unix-время в секундах
+	HeaderNonce     = "X-Signature-Nonce"     // Одноразовое случайное значение
+	HeaderBodyHash  = "X-Content-SHA256"      // SHA-256 тела запроса в шестнадцатеричном виде
+	HeaderSignature = "X-Signature"           // HMAC-SHA256 строки подписи в шестнадцатеричном виде
+)
+
+// Signer подписывает HTTP-запросы секретом HMAC.
+type Signer struct {
+	KeyID  string           // Идентификатор ключа, выданный сервером
+	Secret []byte           // Секрет подписи
+	Now    func() time.Time // Источник времени; по умолчанию time.Now
+}
+
+// Sign вычисляет подпись запроса и записывает её в заголовки.
+// Тело запроса читается целиком и подменяется копией.
+func (s *Signer) Sign(req *http.Request) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+
+	timestamp := strconv.FormatInt(now().Unix(), 10)
+	bodyHash := HashBody(body)
+	nonceHex := hex.EncodeToString(nonce)
+
+	req.Header.Set(HeaderKey, s.KeyID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderNonce, nonceHex)
+	req.Header.Set(HeaderBodyHash, bodyHash)
+	req.Header.Set(HeaderSignature, Signature(s.Secret, StringToSign(req.Method, RequestTarget(req), timestamp, nonceHex, bodyHash)))
+	return nil
+}
+
+// Transport возвращает http.RoundTripper, подписывающий каждый запрос.
+// Если base равен nil, используется http.DefaultTransport.
+func (s *Signer) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripper(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		if err := s.Sign(req); err != nil {
+			return nil, err
+		}
+		return base.RoundTrip(req)
+	})
+}
+
+// roundTripper позволяет использовать функцию как http.RoundTripper.
+type roundTripper func(*http.Request) (*http.Response, error)
+
+// RoundTrip вызывает саму функцию.
+func (f roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// StringToSign собирает каноническую строку, над которой вычисляется подпись.
+func StringToSign(method, target, timestamp, nonce, bodyHash string) string {
+	return strings.Join([]string{strings.ToUpper(method), target, timestamp, nonce, bodyHash}, "\n")
+}
+
+// RequestTarget возвращает путь запроса вместе со строкой запроса в том виде,
+// в каком он передаётся по сети.
+func RequestTarget(req *http.Request) string {
+	return req.URL.RequestURI()
+}
+
+// Signature вычисляет HMAC-SHA256 строки s секретом secret в шестнадцатеричном виде.
+func Signature(secret []byte, s string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HashBody возвращает SHA-256 тела запроса в шестнадцатеричном виде.
+func HashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
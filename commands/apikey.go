@@ -0,0 +1,106 @@
+// Package commands содержит консольные команды приложения.
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"server/auth"
+	"server/database"
+	"server/models"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func init() {
+	Register(Command{
+		Name:  "apikey",
+		Usage: "apikey issue [--signing] <name> | apikey revoke <id> | apikey list",
+		Run:   apiKey,
+	})
+}
+
+// apiKey управляет ключами API: выпуск, отзыв и просмотр списка.
+func apiKey(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: apikey issue [--signing] <name> | apikey revoke <id> | apikey list")
+	}
+
+	switch args[0] {
+	case "issue":
+		signing := len(args) > 1 && args[1] == "--signing"
+		if signing {
+			args = args[1:]
+		}
+		name := strings.TrimSpace(strings.Join(args[1:], " "))
+		if name == "" {
+			return errors.New("usage: apikey issue [--signing] <name>")
+		}
+		if signing {
+			key, secret, err := auth.IssueSigningKey(database.DB, name)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Issued signing key #%d (%s)\nKey ID: %d\nSecret: %s\nStore it now: the secret is not printed again.\n"+
+				"The server keeps it unencrypted to verify signatures, so protect database access and backups.\n", key.ID, key.Name, key.ID, secret)
+			return nil
+		}
+		key, token, err := auth.IssueAPIKey(database.DB, name)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Issued API key #%d (%s)\n%s\nStore it now: the key cannot be shown again.\n", key.ID, key.Name, token)
+		return nil
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New("usage: apikey revoke <id>")
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid id %q", args[1])
+		}
+		if err := models.RevokeAPIKey(database.DB, uint(id)); err != nil {
+			return err
+		}
+		fmt.Printf("Revoked API key #%d\n", id)
+		return nil
+	case "list":
+		keys, err := models.GetAllAPIKeys(database.DB)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tPREFIX\tTYPE\tLAST USED\tSTATUS")
+		for _, key := range keys {
+			fmt.Fprintf(w, "%d\t%s\t%s…\t%s\t%s\t%s\n", key.ID, key.Name, key.Prefix, keyType(key), formatTime(key.LastUsedAt), keyStatus(key))
+		}
+		return w.Flush()
+	}
+	return fmt.Errorf("unknown apikey subcommand %q", args[0])
+}
+
+// keyType возвращает способ аутентификации ключа для вывода в списке.
+func keyType(key models.APIKey) string {
+	if key.Secret != "" {
+		return "signing"
+	}
+	return "bearer"
+}
+
+// keyStatus возвращает состояние ключа для вывода в списке.
+func keyStatus(key models.APIKey) string {
+	if key.RevokedAt != nil {
+		return "revoked " + key.RevokedAt.Format(time.DateTime)
+	}
+	return "active"
+}
+
+// formatTime форматирует необязательное время для вывода в таблице.
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+	return t.Format(time.DateTime)
+}
@@ -0,0 +1,52 @@
+// Package commands содержит консольные команды приложения,
+// запускаемые как подкоманды исполняемого файла сервера.
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Command описывает консольную команду.
+type Command struct {
+	Name  string                    // Имя команды, например "apikey"
+	Usage string                    // Краткая справка по аргументам
+	Run   func(args []string) error // Обработчик, получающий аргументы после имени команды
+}
+
+// registry содержит зарегистрированные команды по именам.
+var registry = map[string]Command{}
+
+// Register регистрирует команду. Вызывается из init() файлов с командами.
+func Register(c Command) {
+	registry[c.Name] = c
+}
+
+// Run выполняет команду, имя которой передано первым аргументом.
+func Run(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no command given\n%s", Usage())
+	}
+	c, ok := registry[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n%s", args[0], Usage())
+	}
+	return c.Run(args[1:])
+}
+
+// Usage возвращает справку по всем зарегистрированным командам.
+func Usage() string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("Commands:\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %s\n", registry[name].Usage)
+	}
+	return b.String()
+}
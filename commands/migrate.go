@@ -0,0 +1,105 @@
+// Package commands содержит консольные команды приложения.
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"server/database"
+	"server/migrate"
+	"server/migrations"
+	"server/rbac"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = "migrate up [n] | migrate down [n] | migrate status | migrate redo"
+
+func init() {
+	Register(Command{
+		Name:  "migrate",
+		Usage: migrateUsage,
+		Run:   migrateCommand,
+	})
+}
+
+// migrateCommand применяет, откатывает и показывает миграции схемы.
+func migrateCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: " + migrateUsage)
+	}
+
+	steps := 0
+	if len(args) == 2 && (args[0] == "up" || args[0] == "down") {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid number of steps %q", args[1])
+		}
+		steps = n
+	} else if len(args) != 1 {
+		return errors.New("usage: " + migrateUsage)
+	}
+
+	m := migrate.New(database.DB, migrations.All())
+	switch args[0] {
+	case "up":
+		done, err := m.Up(steps)
+		printMigrations("Applied", done)
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println("Nothing to migrate")
+		}
+		return rbac.EnsureDefaultRoles(database.DB)
+	case "down":
+		done, err := m.Down(steps)
+		printMigrations("Rolled back", done)
+		if err == nil && len(done) == 0 {
+			fmt.Println("Nothing to roll back")
+		}
+		return err
+	case "redo":
+		migration, err := m.Redo()
+		if err != nil {
+			return err
+		}
+		if migration == nil {
+			fmt.Println("Nothing to redo")
+			return nil
+		}
+		fmt.Printf("Redone %d %s\n", migration.Version, migration.Name)
+		return nil
+	case "status":
+		statuses, err := m.Status()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+		for _, s := range statuses {
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, migrationStatus(s))
+		}
+		return w.Flush()
+	}
+	return fmt.Errorf("unknown migrate subcommand %q", args[0])
+}
+
+// printMigrations выводит список применённых или откаченных миграций.
+func printMigrations(action string, done []migrate.Migration) {
+	for _, migration := range done {
+		fmt.Printf("%s %d %s\n", action, migration.Version, migration.Name)
+	}
+}
+
+// migrationStatus возвращает состояние миграции для вывода в списке.
+func migrationStatus(s migrate.Status) string {
+	switch {
+	case s.Missing:
+		return "applied " + s.AppliedAt.Format(time.DateTime) + " (missing in code)"
+	case s.AppliedAt != nil:
+		return "applied " + s.AppliedAt.Format(time.DateTime)
+	}
+	return "pending"
+}
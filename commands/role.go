@@ -0,0 +1,114 @@
+// Package commands содержит консольные команды приложения.
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"server/database"
+	"server/models"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const roleUsage = "role list | role grant <role> <permission> | role assign|unassign user <email> <role> | role assign|unassign apikey <id> <role>"
+
+func init() {
+	Register(Command{
+		Name:  "role",
+		Usage: roleUsage,
+		Run:   role,
+	})
+}
+
+// role управляет ролями: просмотр, выдача разрешений и назначение пользователям и ключам API.
+func role(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: " + roleUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		roles, err := models.GetAllRoles(database.DB)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ROLE\tPERMISSIONS")
+		for _, r := range roles {
+			names := make([]string, len(r.Permissions))
+			for i, p := range r.Permissions {
+				names[i] = p.Name
+			}
+			fmt.Fprintf(w, "%s\t%s\n", r.Name, strings.Join(names, ", "))
+		}
+		return w.Flush()
+	case "grant":
+		if len(args) != 3 {
+			return errors.New("usage: role grant <role> <permission>")
+		}
+		r, err := models.EnsureRole(database.DB, args[1], args[2])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Granted %s to role %s\n", args[2], r.Name)
+		return nil
+	case "assign", "unassign":
+		if len(args) != 4 {
+			return errors.New("usage: role " + args[0] + " user <email> <role> | role " + args[0] + " apikey <id> <role>")
+		}
+		r, err := models.GetRoleByName(database.DB, args[3])
+		if err != nil {
+			return fmt.Errorf("role %q: %w", args[3], err)
+		}
+		return assignRole(args[0] == "assign", args[1], args[2], r)
+	}
+	return fmt.Errorf("unknown role subcommand %q", args[0])
+}
+
+// assignRole назначает или снимает роль r у пользователя или ключа API.
+func assignRole(assign bool, kind, subject string, r *models.Role) error {
+	action := "Unassigned"
+	if assign {
+		action = "Assigned"
+	}
+
+	switch kind {
+	case "user":
+		u, err := models.GetUserByEmail(database.DB, subject)
+		if err != nil {
+			return fmt.Errorf("user %q: %w", subject, err)
+		}
+		if assign {
+			err = models.AssignUserRole(database.DB, u, r)
+		} else {
+			err = models.UnassignUserRole(database.DB, u, r)
+		}
+		if err != nil {
+			return err
+		}
+	case "apikey":
+		id, err := strconv.Atoi(subject)
+		if err != nil {
+			return fmt.Errorf("invalid id %q", subject)
+		}
+		key, err := models.GetAPIKeyByID(database.DB, uint(id))
+		if err != nil {
+			return fmt.Errorf("api key #%d: %w", id, err)
+		}
+		if assign {
+			err = models.AssignAPIKeyRole(database.DB, key, r)
+		} else {
+			err = models.UnassignAPIKeyRole(database.DB, key, r)
+		}
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown subject kind %q, expected user or apikey", kind)
+	}
+
+	fmt.Printf("%s role %s (%s %s)\n", action, r.Name, kind, subject)
+	return nil
+}
@@ -0,0 +1,92 @@
+// Package commands содержит консольные команды приложения.
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"server/config"
+	"server/database"
+	"server/seed"
+	"strings"
+)
+
+const seedUsage = "seed [--dir <path>] [set...] | seed list [--dir <path>]"
+
+func init() {
+	Register(Command{
+		Name:  "seed",
+		Usage: seedUsage,
+		Run:   seedCommand,
+	})
+}
+
+// seedCommand загружает наборы фикстур. Без указания набора используется набор
+// окружения APP_ENV (см. seed.EnvSets). Наборы берутся из встроенных фикстур
+// или из каталога --dir (по умолчанию FIXTURES_DIR), где каждый набор — подкаталог.
+func seedCommand(args []string) error {
+	fsys := seed.Bundled()
+	dir := config.String("FIXTURES_DIR", "")
+	list := false
+
+	var sets []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--dir":
+			if i+1 == len(args) {
+				return errors.New("usage: " + seedUsage)
+			}
+			i++
+			dir = args[i]
+		case args[i] == "list" && i == 0:
+			list = true
+		case strings.HasPrefix(args[i], "-"):
+			return fmt.Errorf("unknown flag %q\nusage: %s", args[i], seedUsage)
+		default:
+			sets = append(sets, args[i])
+		}
+	}
+	if dir != "" {
+		fsys = os.DirFS(dir)
+	}
+
+	if list {
+		names, err := seed.Sets(fsys)
+		if err != nil {
+			return err
+		}
+		fmt.Println(strings.Join(names, "\n"))
+		return nil
+	}
+
+	if len(sets) == 0 {
+		env := config.String("APP_ENV", "development")
+		set, ok := seed.EnvSets[env]
+		if !ok {
+			return fmt.Errorf("no default fixture set for APP_ENV=%s, name one explicitly\nusage: %s", env, seedUsage)
+		}
+		sets = []string{set}
+	}
+
+	for _, set := range sets {
+		if err := seedSet(fsys, set); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedSet загружает один набор фикстур и выводит итог.
+func seedSet(fsys fs.FS, set string) error {
+	fixtures, err := seed.Load(fsys, set)
+	if err != nil {
+		return err
+	}
+	result, err := seed.Apply(database.DB, fixtures)
+	if err != nil {
+		return fmt.Errorf("seed %s: %w", set, err)
+	}
+	fmt.Printf("Seeded %s: %d created, %d updated\n", set, result.Created, result.Updated)
+	return nil
+}
@@ -0,0 +1,74 @@
+// Package commands содержит консольные команды приложения.
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"server/auth"
+	"server/database"
+	"server/models"
+	"strings"
+)
+
+func init() {
+	Register(Command{
+		Name:  "user",
+		Usage: "user create <email> [name] | user passwd <email>  (password is read from stdin)",
+		Run:   user,
+	})
+}
+
+// user управляет учётными записями пользователей: создание и смена пароля.
+// Пароль читается из первой строки стандартного ввода.
+func user(args []string) error {
+	if len(args) < 2 {
+		return errors.New("usage: user create <email> [name] | user passwd <email>")
+	}
+	email := strings.TrimSpace(args[1])
+
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "create":
+		u := &models.User{Email: email, Name: strings.Join(args[2:], " "), PasswordHash: hash}
+		if err := models.CreateUser(database.DB, u); err != nil {
+			return err
+		}
+		fmt.Printf("Created user #%d (%s)\n", u.ID, u.Email)
+		return nil
+	case "passwd":
+		u, err := models.GetUserByEmail(database.DB, email)
+		if err != nil {
+			return err
+		}
+		if err := models.UpdateUserPassword(database.DB, u.ID, hash); err != nil {
+			return err
+		}
+		fmt.Printf("Password of user #%d updated\n", u.ID)
+		return nil
+	}
+	return fmt.Errorf("unknown user subcommand %q", args[0])
+}
+
+// readPassword читает пароль из первой строки стандартного ввода.
+func readPassword() (string, error) {
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if len(password) < 8 {
+		return "", errors.New("password must be at least 8 characters")
+	}
+	return password, nil
+}
@@ -0,0 +1,82 @@
+// Package config предоставляет доступ к параметрам конфигурации приложения,
+// заданным через переменные окружения или файл конфигурации.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	fileMu     sync.RWMutex
+	fileValues map[string]string
+)
+
+// Load загружает файл конфигурации path — JSON-объект с параметрами под именами
+// переменных окружения, например {"DB_HOST": "db", "DB_MAX_OPEN_CONNS": 50}.
+// Переменные окружения имеют приоритет над файлом. Пустой path ничего не загружает.
+func Load(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, val := range raw {
+		switch v := val.(type) {
+		case string:
+			values[key] = v
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		case map[string]any:
+			return fmt.Errorf("%s: %s: nested objects are not supported", path, key)
+		case nil:
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+
+	fileMu.Lock()
+	fileValues = values
+	fileMu.Unlock()
+	return nil
+}
+
+// Secret возвращает секрет key. Если задан параметр key+"_FILE", секрет читается
+// из указанного в нём файла (например, Docker secrets), иначе берётся как String.
+// Завершающие пробелы и переводы строк в файле отбрасываются.
+func Secret(key string, defaultValue string) (string, error) {
+	if path := String(key+"_FILE", ""); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("%s_FILE: %w", key, err)
+		}
+		return strings.TrimRight(string(data), "\r\n\t "), nil
+	}
+	return String(key, defaultValue), nil
+}
+
+// lookup возвращает значение параметра key из окружения или файла конфигурации.
+func lookup(key string) string {
+	if val := os.Getenv(key); strings.TrimSpace(val) != "" {
+		return val
+	}
+	fileMu.RLock()
+	defer fileMu.RUnlock()
+	return fileValues[key]
+}
@@ -0,0 +1,66 @@
+// Package config предоставляет доступ к параметрам конфигурации приложения,
+// заданным через переменные окружения или файл конфигурации.
+package config
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// String возвращает значение переменной окружения key или, если она не задана,
+// одноимённого параметра файла конфигурации; defaultValue, если значение не задано или пусто.
+func String(key string, defaultValue string) string {
+	val := strings.TrimSpace(lookup(key))
+	if val == "" {
+		return defaultValue
+	}
+	return val
+}
+
+// List возвращает значения переменной окружения key, разделённые запятыми.
+// Пустые элементы отбрасываются.
+func List(key string) []string {
+	val := lookup(key)
+	if strings.TrimSpace(val) == "" {
+		return nil
+	}
+
+	var items []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Int возвращает целочисленное значение переменной окружения key,
+// или defaultValue, если переменная не задана или некорректна.
+func Int(key string, defaultValue int) int {
+	val, err := strconv.Atoi(String(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return val
+}
+
+// Bool возвращает логическое значение переменной окружения key,
+// или defaultValue, если переменная не задана или некорректна.
+func Bool(key string, defaultValue bool) bool {
+	val, err := strconv.ParseBool(String(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return val
+}
+
+// Duration возвращает длительность из переменной окружения key (например "30s"),
+// или defaultValue, если переменная не задана или некорректна.
+func Duration(key string, defaultValue time.Duration) time.Duration {
+	val, err := time.ParseDuration(String(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return val
+}
@@ -0,0 +1,88 @@
+// Package controllers содержит HTTP-обработчики для просмотра журнала изменений.
+package controllers
+
+import (
+	"net/http"
+	"server/models"
+	"server/repository"
+	"server/request"
+	"server/types"
+	"strconv"
+	"time"
+)
+
+// Ограничения на размер страницы журнала.
+const (
+	auditDefaultLimit = 50
+	auditMaxLimit     = 500
+)
+
+// AuditController обрабатывает запросы к журналу изменений через хранилище audit.
+type AuditController struct {
+	audit repository.AuditRepository
+}
+
+// NewAuditController создаёт контроллер журнала изменений поверх хранилища audit.
+func NewAuditController(audit repository.AuditRepository) *AuditController {
+	return &AuditController{audit: audit}
+}
+
+// GetAuditEntries возвращает записи журнала изменений, начиная с самых новых.
+// Поддерживаемые параметры запроса: entity, entity_id, action, actor_kind, actor_id,
+// request_id, from и to (RFC 3339), limit (от 1, больше auditMaxLimit ограничивается) и offset.
+func (c *AuditController) GetAuditEntries(r *request.Request, params map[string]string) types.JsonResponse {
+	filter := models.AuditFilter{
+		Entity:    r.Query("entity", ""),
+		Action:    r.Query("action", ""),
+		ActorKind: r.Query("actor_kind", ""),
+		RequestID: r.Query("request_id", ""),
+		Limit:     auditDefaultLimit,
+	}
+
+	var err error
+	uints := map[string]*uint{"entity_id": &filter.EntityID, "actor_id": &filter.ActorID}
+	for name, dst := range uints {
+		if v := r.Query(name, ""); v != "" {
+			n, err := strconv.ParseUint(v, 10, 0)
+			if err != nil {
+				return invalidAuditFilter(r, name)
+			}
+			*dst = uint(n)
+		}
+	}
+
+	times := map[string]*time.Time{"from": &filter.From, "to": &filter.To}
+	for name, dst := range times {
+		if v := r.Query(name, ""); v != "" {
+			if *dst, err = time.Parse(time.RFC3339, v); err != nil {
+				return invalidAuditFilter(r, name)
+			}
+		}
+	}
+
+	// Наименьшие допустимые значения параметров
+	ints := map[string]struct {
+		dst *int
+		min int
+	}{"limit": {&filter.Limit, 1}, "offset": {&filter.Offset, 0}}
+	for name, param := range ints {
+		if v := r.Query(name, ""); v != "" {
+			if *param.dst, err = strconv.Atoi(v); err != nil || *param.dst < param.min {
+				return invalidAuditFilter(r, name)
+			}
+		}
+	}
+	filter.Limit = min(filter.Limit, auditMaxLimit)
+
+	entries, err := c.audit.List(r.Context(), filter)
+	if err != nil {
+		return types.JsonResponse{Status: "error", Message: err.Error()}
+	}
+
+	return types.JsonResponse{Status: "success", Data: entries}
+}
+
+// invalidAuditFilter возвращает ответ 400 о некорректном параметре фильтра.
+func invalidAuditFilter(r *request.Request, name string) types.JsonResponse {
+	return types.JsonResponse{Status: "error", Message: r.T("audit.invalid_filter", name), Code: http.StatusBadRequest}
+}
@@ -0,0 +1,70 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"server/controllers"
+	"server/models"
+	"server/repository"
+	"server/request"
+	"testing"
+	"time"
+)
+
+// newAuditController создаёт контроллер журнала с записями в памяти.
+func newAuditController(entries ...models.AuditEntry) *controllers.AuditController {
+	audit := repository.NewMemoryAudit()
+	audit.Add(entries...)
+	return controllers.NewAuditController(audit)
+}
+
+// auditRequest создаёт запрос к журналу со строкой запроса query.
+func auditRequest(query string) *request.Request {
+	return request.InitRequest(httptest.NewRequest(http.MethodGet, "/audit/?"+query, nil))
+}
+
+func TestGetAuditEntries(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	audit := newAuditController(
+		models.AuditEntry{Action: "create", Entity: "beer", EntityID: 1, CreatedAt: start},
+		models.AuditEntry{Action: "update", Entity: "beer", EntityID: 1, ActorKind: "user", ActorID: 7, CreatedAt: start.Add(time.Hour)},
+		models.AuditEntry{Action: "create", Entity: "snack", EntityID: 1, CreatedAt: start.Add(2 * time.Hour)},
+	)
+
+	tests := []struct {
+		query string
+		want  []uint // Идентификаторы записей в порядке ответа
+	}{
+		{"", []uint{3, 2, 1}},
+		{"entity=beer", []uint{2, 1}},
+		{"entity=beer&entity_id=1&action=update", []uint{2}},
+		{"actor_kind=user&actor_id=7", []uint{2}},
+		{"from=2026-01-01T01:00:00Z&to=2026-01-01T02:00:00Z", []uint{2}},
+		{"limit=1&offset=1", []uint{2}},
+		{"limit=100000", []uint{3, 2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			resp := audit.GetAuditEntries(auditRequest(tt.query), nil)
+			expectSuccess(t, resp)
+			entries := resp.Data.([]models.AuditEntry)
+			if len(entries) != len(tt.want) {
+				t.Fatalf("expected %d entries, got %+v", len(tt.want), entries)
+			}
+			for i, entry := range entries {
+				if entry.ID != tt.want[i] {
+					t.Fatalf("expected entries %v, got %+v", tt.want, entries)
+				}
+			}
+		})
+	}
+}
+
+func TestGetAuditEntriesInvalidFilter(t *testing.T) {
+	audit := newAuditController()
+	for _, query := range []string{"limit=0", "limit=-1", "limit=x", "offset=-1", "entity_id=x", "actor_id=-1", "from=yesterday"} {
+		t.Run(query, func(t *testing.T) {
+			expectError(t, audit.GetAuditEntries(auditRequest(query), nil), http.StatusBadRequest)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Package controllers содержит HTTP-обработчики для аутентификации пользователей.
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"server/auth"
+	"server/request"
+	"server/types"
+	"strings"
+)
+
+// credentials — тело запроса на вход.
+type credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// refreshInput — тело запросов на обновление токенов и выход.
+type refreshInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Login проверяет email и пароль и возвращает пару access- и refresh-токенов.
+func Login(r *request.Request, params map[string]string) types.JsonResponse {
+	var input credentials
+	if err := r.Json(&input); err != nil {
+		return types.JsonResponse{Status: "error", Message: r.T("request.invalid_input", err), Code: http.StatusBadRequest}
+	}
+
+	pair, err := auth.Tokens.Login(r.Context(), strings.TrimSpace(input.Email), input.Password)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			return types.JsonResponse{Status: "error", Message: r.T("auth.invalid_credentials"), Code: http.StatusUnauthorized}
+		}
+		log.Printf("auth: login failed: %v", err)
+		return types.JsonResponse{Status: "error", Message: r.T("auth.failed"), Code: http.StatusInternalServerError}
+	}
+
+	return types.JsonResponse{Status: "success", Data: pair}
+}
+
+// RefreshToken обменивает refresh-токен на новую пару токенов.
+// Повторное использование токена отзывает все токены его цепочки.
+func RefreshToken(r *request.Request, params map[string]string) types.JsonResponse {
+	var input refreshInput
+	if err := r.Json(&input); err != nil {
+		return types.JsonResponse{Status: "error", Message: r.T("request.invalid_input", err), Code: http.StatusBadRequest}
+	}
+
+	pair, err := auth.Tokens.Refresh(r.Context(), input.RefreshToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) || errors.Is(err, auth.ErrTokenReused) {
+			return types.JsonResponse{Status: "error", Message: r.T("auth.invalid_token"), Code: http.StatusUnauthorized}
+		}
+		log.Printf("auth: refresh failed: %v", err)
+		return types.JsonResponse{Status: "error", Message: r.T("auth.failed"), Code: http.StatusInternalServerError}
+	}
+
+	return types.JsonResponse{Status: "success", Data: pair}
+}
+
+// Logout отзывает refresh-токен вместе со всей его цепочкой ротации.
+func Logout(r *request.Request, params map[string]string) types.JsonResponse {
+	var input refreshInput
+	if err := r.Json(&input); err != nil {
+		return types.JsonResponse{Status: "error", Message: r.T("request.invalid_input", err), Code: http.StatusBadRequest}
+	}
+
+	if err := auth.Tokens.Revoke(r.Context(), input.RefreshToken); err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return types.JsonResponse{Status: "error", Message: r.T("auth.invalid_token"), Code: http.StatusUnauthorized}
+		}
+		log.Printf("auth: logout failed: %v", err)
+		return types.JsonResponse{Status: "error", Message: r.T("auth.failed"), Code: http.StatusInternalServerError}
+	}
+
+	return types.JsonResponse{Status: "success", Message: r.T("auth.logged_out")}
+}
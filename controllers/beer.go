@@ -2,23 +2,32 @@
 package controllers
 
 import (
-	"server/database"
+	"errors"
+	"net/http"
 	"server/models"
+	"server/repository"
 	"server/request"
 	"server/types"
-	"strconv"
-
-	"gorm.io/gorm"
 )
 
+// BeerController обрабатывает запросы к пиву через хранилище beers.
+// Ответы с одной записью содержат заголовок ETag с её версией для If-Match.
+type BeerController struct {
+	beers repository.BeerRepository
+}
+
+// NewBeerController создаёт контроллер пива поверх хранилища beers.
+func NewBeerController(beers repository.BeerRepository) *BeerController {
+	return &BeerController{beers: beers}
+}
+
 // GetRandomBeer возвращает случайное пиво из базы данных.
 // Если пиво не найдено, возвращает ошибку с соответствующим сообщением.
-func GetRandomBeer(r *request.Request, params map[string]string) types.JsonResponse {
-	var beer models.Beer
-	err := database.DB.Order("RAND()").First(&beer).Error
+func (c *BeerController) GetRandomBeer(r *request.Request, params map[string]string) types.JsonResponse {
+	beer, err := c.beers.Random(r.Context())
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return types.JsonResponse{Status: "error", Message: "No beers found"}
+		if errors.Is(err, repository.ErrNotFound) {
+			return types.JsonResponse{Status: "error", Message: r.T("beer.none")}
 		}
 		return types.JsonResponse{Status: "error", Message: err.Error()}
 	}
@@ -26,71 +35,67 @@ func GetRandomBeer(r *request.Request, params map[string]string) types.JsonRespo
 	return types.JsonResponse{Status: "success", Data: beer}
 }
 
+// GetAllBeers возвращает список пива из базы данных.
+// Параметр запроса q включает полнотекстовый поиск по названию, пивоварне, стилю и описанию.
+func (c *BeerController) GetAllBeers(r *request.Request, params map[string]string) types.JsonResponse {
+	beers, err := c.beers.List(r.Context(), r.Query("q", ""))
+	if err != nil {
+		return types.JsonResponse{Status: "error", Message: err.Error()}
+	}
+
+	return types.JsonResponse{Status: "success", Data: beers}
+}
+
 // StoreBeer создаёт новую запись пива на основе JSON из запроса.
 // Возвращает ошибку, если входные данные некорректны или произошла ошибка базы данных.
-func StoreBeer(r *request.Request, params map[string]string) types.JsonResponse {
+func (c *BeerController) StoreBeer(r *request.Request, params map[string]string) types.JsonResponse {
 	var beer models.Beer
 	if err := r.Json(&beer); err != nil {
-		return types.JsonResponse{Status: "error", Message: "Invalid input: " + err.Error()}
+		return types.JsonResponse{Status: "error", Message: r.T("request.invalid_input", err)}
 	}
 
-	if err := database.DB.Create(&beer).Error; err != nil {
+	if err := beer.Validate(); err != nil {
+		return types.JsonResponse{Status: "error", Message: r.T("validation.failed", r.Localize(err))}
+	}
+
+	if err := c.beers.Create(r.Context(), &beer); err != nil {
 		return types.JsonResponse{Status: "error", Message: err.Error()}
 	}
 
-	return types.JsonResponse{Status: "success", Data: beer}
+	return types.JsonResponse{Status: "success", Data: beer, Header: etag(beer.Version)}
 }
 
 // ShowBeer возвращает пиво по ID, переданному в параметрах маршрута.
 // Возвращает ошибку, если ID отсутствует, некорректен или запись не найдена.
-func ShowBeer(r *request.Request, params map[string]string) types.JsonResponse {
-	idStr, ok := params["id"]
-	if !ok {
-		return types.JsonResponse{Status: "error", Message: "ID parameter is required"}
+func (c *BeerController) ShowBeer(r *request.Request, params map[string]string) types.JsonResponse {
+	beer, resp := c.find(r, params)
+	if beer == nil {
+		return resp
 	}
 
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		return types.JsonResponse{Status: "error", Message: "Invalid ID"}
-	}
-
-	var beer models.Beer
-	if err := database.DB.First(&beer, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return types.JsonResponse{Status: "error", Message: "Beer not found"}
-		}
-		return types.JsonResponse{Status: "error", Message: err.Error()}
-	}
-
-	return types.JsonResponse{Status: "success", Data: beer}
+	return types.JsonResponse{Status: "success", Data: beer, Header: etag(beer.Version)}
 }
 
 // UpdateBeer обновляет существующую запись пива по ID.
 // Возвращает ошибку, если ID отсутствует, некорректен, запись не найдена или входные данные некорректны.
-func UpdateBeer(r *request.Request, params map[string]string) types.JsonResponse {
-	idStr, ok := params["id"]
-	if !ok {
-		return types.JsonResponse{Status: "error", Message: "ID parameter is required"}
-	}
-
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		return types.JsonResponse{Status: "error", Message: "Invalid ID"}
-	}
-
-	var beer models.Beer
-	if err := database.DB.First(&beer, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return types.JsonResponse{Status: "error", Message: "Beer not found"}
-		}
-		return types.JsonResponse{Status: "error", Message: err.Error()}
+// Ожидаемая версия записи передаётся заголовком If-Match или полем Version; без неё возвращается 428,
+// а если запись уже изменена другим запросом — 409.
+func (c *BeerController) UpdateBeer(r *request.Request, params map[string]string) types.JsonResponse {
+	beer, resp := c.find(r, params)
+	if beer == nil {
+		return resp
 	}
 
 	var input models.Beer
 	if err := r.Json(&input); err != nil {
-		return types.JsonResponse{Status: "error", Message: "Invalid input: " + err.Error()}
+		return types.JsonResponse{Status: "error", Message: r.T("request.invalid_input", err)}
 	}
 
+	version, resp := expectedVersion(r, input.Version)
+	if version == 0 {
+		return resp
+	}
+	beer.Version = version
 	beer.Name = input.Name
 	beer.Brewery = input.Brewery
 	beer.Style = input.Style
@@ -99,29 +104,141 @@ func UpdateBeer(r *request.Request, params map[string]string) types.JsonResponse
 	beer.IBU = input.IBU
 	beer.EBC = input.EBC
 
-	if err := database.DB.Save(&beer).Error; err != nil {
-		return types.JsonResponse{Status: "error", Message: err.Error()}
+	if err := beer.Validate(); err != nil {
+		return types.JsonResponse{Status: "error", Message: r.T("validation.failed", r.Localize(err))}
 	}
 
-	return types.JsonResponse{Status: "success", Data: beer}
+	return c.save(r, beer)
+}
+
+// PatchBeer частично обновляет запись пива по ID.
+// Тело запроса применяется как JSON Merge Patch (RFC 7396) или JSON Patch (RFC 6902)
+// в зависимости от Content-Type (для других типов возвращается 415), результат проходит
+// валидацию перед сохранением. Ожидаемая версия записи передаётся заголовком If-Match,
+// полем Version в merge patch или операцией test/replace пути /Version в JSON Patch.
+func (c *BeerController) PatchBeer(r *request.Request, params map[string]string) types.JsonResponse {
+	beer, resp := c.find(r, params)
+	if beer == nil {
+		return resp
+	}
+
+	before := *beer
+	if err := r.Patch(beer); err != nil {
+		return patchError(r, err)
+	}
+	// Версия из патча учитывается, только если патч её задаёт или проверяет операцией test
+	var patchVersion uint
+	if r.PatchMentions("Version") {
+		patchVersion = beer.Version
+	}
+	version, resp := expectedVersion(r, patchVersion)
+	if version == 0 {
+		return resp
+	}
+	beer.Version = version
+
+	// Служебные поля не изменяются через патч
+	beer.ID = before.ID
+	beer.CreatedAt = before.CreatedAt
+	beer.UpdatedAt = before.UpdatedAt
+	beer.DeletedAt = before.DeletedAt
+
+	if err := beer.Validate(); err != nil {
+		return types.JsonResponse{Status: "error", Message: r.T("validation.failed", r.Localize(err))}
+	}
+
+	return c.save(r, beer)
 }
 
 // DeleteBeer удаляет запись пива по ID.
-// Возвращает ошибку, если ID отсутствует, некорректен или произошла ошибка при удалении.
-func DeleteBeer(r *request.Request, params map[string]string) types.JsonResponse {
-	idStr, ok := params["id"]
-	if !ok {
-		return types.JsonResponse{Status: "error", Message: "ID parameter is required"}
+// Возвращает ошибку, если ID отсутствует, некорректен, запись не найдена или произошла ошибка при удалении.
+func (c *BeerController) DeleteBeer(r *request.Request, params map[string]string) types.JsonResponse {
+	beer, resp := c.find(r, params)
+	if beer == nil {
+		return resp
 	}
 
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		return types.JsonResponse{Status: "error", Message: "Invalid ID"}
+	if err := c.beers.Delete(r.Context(), beer.ID); err != nil {
+		return c.error(r, err)
 	}
 
-	if err := database.DB.Delete(&models.Beer{}, id).Error; err != nil {
+	return types.JsonResponse{Status: "success", Message: r.T("beer.deleted")}
+}
+
+// GetTrashedBeers возвращает записи пива из корзины — мягко удалённые, но ещё не удалённые окончательно.
+func (c *BeerController) GetTrashedBeers(r *request.Request, params map[string]string) types.JsonResponse {
+	beers, err := c.beers.Trash(r.Context())
+	if err != nil {
 		return types.JsonResponse{Status: "error", Message: err.Error()}
 	}
 
-	return types.JsonResponse{Status: "success", Message: "Beer deleted"}
+	return types.JsonResponse{Status: "success", Data: beers}
+}
+
+// RestoreBeer восстанавливает запись пива из корзины по ID.
+// Возвращает ошибку, если ID отсутствует, некорректен или записи нет в корзине.
+func (c *BeerController) RestoreBeer(r *request.Request, params map[string]string) types.JsonResponse {
+	id, resp := parseID(r, params)
+	if id == 0 {
+		return resp
+	}
+
+	beer, err := c.beers.Restore(r.Context(), id)
+	if err != nil {
+		return c.error(r, err)
+	}
+
+	return types.JsonResponse{Status: "success", Data: beer, Header: etag(beer.Version)}
+}
+
+// ForceDeleteBeer окончательно удаляет запись пива по ID, в том числе из корзины.
+// Возвращает ошибку, если ID отсутствует, некорректен или запись не найдена.
+func (c *BeerController) ForceDeleteBeer(r *request.Request, params map[string]string) types.JsonResponse {
+	id, resp := parseID(r, params)
+	if id == 0 {
+		return resp
+	}
+
+	if err := c.beers.ForceDelete(r.Context(), id); err != nil {
+		return c.error(r, err)
+	}
+
+	return types.JsonResponse{Status: "success", Message: r.T("beer.purged")}
+}
+
+// find загружает пиво по параметру маршрута id.
+// Если загрузить запись не удалось, возвращает nil и ответ с ошибкой.
+func (c *BeerController) find(r *request.Request, params map[string]string) (*models.Beer, types.JsonResponse) {
+	id, resp := parseID(r, params)
+	if id == 0 {
+		return nil, resp
+	}
+
+	beer, err := c.beers.Find(r.Context(), id)
+	if err != nil {
+		return nil, c.error(r, err)
+	}
+	return beer, types.JsonResponse{}
+}
+
+// save сохраняет изменённое пиво и возвращает его в ответе.
+func (c *BeerController) save(r *request.Request, beer *models.Beer) types.JsonResponse {
+	if err := c.beers.Update(r.Context(), beer); err != nil {
+		return c.error(r, err)
+	}
+
+	return types.JsonResponse{Status: "success", Data: beer, Header: etag(beer.Version)}
+}
+
+// error преобразует ошибку хранилища в ответ. При конфликте версий
+// возвращается 409 с текущим состоянием записи.
+func (c *BeerController) error(r *request.Request, err error) types.JsonResponse {
+	var conflict *repository.ConflictError
+	if errors.As(err, &conflict) {
+		return types.JsonResponse{Status: "error", Message: r.T("beer.conflict"), Data: conflict.Current, Code: http.StatusConflict}
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return types.JsonResponse{Status: "error", Message: r.T("beer.not_found")}
+	}
+	return types.JsonResponse{Status: "error", Message: err.Error()}
 }
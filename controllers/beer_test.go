@@ -0,0 +1,191 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"server/app"
+	"server/controllers"
+	"server/models"
+	"server/patch"
+	"server/request"
+	"server/types"
+	"strings"
+	"testing"
+)
+
+// Заголовки Content-Type для патчей.
+var (
+	mergePatch = []string{"Content-Type", patch.MergePatchType}
+	jsonPatch  = []string{"Content-Type", patch.JSONPatchType}
+)
+
+// newRequest создаёт запрос с телом body и заголовками headers вида "Имя", "значение".
+func newRequest(method, body string, headers ...string) *request.Request {
+	req := httptest.NewRequest(method, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	return request.InitRequest(req)
+}
+
+// id возвращает параметры маршрута с идентификатором записи.
+func id(v string) map[string]string {
+	return map[string]string{"id": v}
+}
+
+// expectSuccess проверяет, что ответ успешный.
+func expectSuccess(t *testing.T, resp types.JsonResponse) {
+	t.Helper()
+	if resp.Status != "success" {
+		t.Fatalf("expected success, got %q: %s", resp.Status, resp.Message)
+	}
+}
+
+// expectError проверяет, что ответ содержит ошибку с HTTP-кодом code (0 — код по умолчанию).
+func expectError(t *testing.T, resp types.JsonResponse, code int) {
+	t.Helper()
+	if resp.Status != "error" {
+		t.Fatalf("expected error, got %q", resp.Status)
+	}
+	if resp.Code != code {
+		t.Fatalf("expected code %d, got %d: %s", code, resp.Code, resp.Message)
+	}
+}
+
+// expectETag проверяет заголовок ETag ответа с записью версии version.
+func expectETag(t *testing.T, resp types.JsonResponse, version string) {
+	t.Helper()
+	if got := resp.Header.Get("ETag"); got != `W/"`+version+`"` {
+		t.Fatalf("expected ETag W/%q, got %q", version, got)
+	}
+}
+
+// newBeerController создаёт контроллер пива с хранилищем в памяти и одной записью версии 1.
+func newBeerController(t *testing.T) *controllers.BeerController {
+	t.Helper()
+	beers := controllers.NewBeerController(app.NewInMemory().Beers)
+	expectSuccess(t, beers.StoreBeer(newRequest(http.MethodPost, `{"Name":"Pilsner","Brewery":"Prazdroj","Style":"Lager"}`), nil))
+	return beers
+}
+
+func TestStoreBeer(t *testing.T) {
+	beers := newBeerController(t)
+
+	resp := beers.ShowBeer(newRequest(http.MethodGet, ""), id("1"))
+	expectSuccess(t, resp)
+	expectETag(t, resp, "1")
+	beer := resp.Data.(*models.Beer)
+	if beer.Name != "Pilsner" || beer.Version != 1 {
+		t.Fatalf("unexpected beer: %+v", beer)
+	}
+
+	expectError(t, beers.StoreBeer(newRequest(http.MethodPost, `{"Brewery":"Nameless"}`), nil), 0)
+}
+
+func TestUpdateBeerRequiresVersion(t *testing.T) {
+	beers := newBeerController(t)
+
+	expectError(t, beers.UpdateBeer(newRequest(http.MethodPut, `{"Name":"Porter"}`), id("1")), http.StatusPreconditionRequired)
+	expectError(t, beers.UpdateBeer(newRequest(http.MethodPut, `{"Name":"Porter"}`, "If-Match", "abc"), id("1")), http.StatusBadRequest)
+
+	resp := beers.UpdateBeer(newRequest(http.MethodPut, `{"Name":"Porter","Version":1}`), id("1"))
+	expectSuccess(t, resp)
+	expectETag(t, resp, "2")
+	if beer := resp.Data.(*models.Beer); beer.Name != "Porter" || beer.Version != 2 {
+		t.Fatalf("unexpected beer after update: %+v", beer)
+	}
+
+	resp = beers.UpdateBeer(newRequest(http.MethodPut, `{"Name":"Stout"}`, "If-Match", `"2"`), id("1"))
+	expectSuccess(t, resp)
+	if beer := resp.Data.(*models.Beer); beer.Version != 3 {
+		t.Fatalf("expected version 3, got %d", beer.Version)
+	}
+}
+
+func TestUpdateBeerConflict(t *testing.T) {
+	beers := newBeerController(t)
+	expectSuccess(t, beers.UpdateBeer(newRequest(http.MethodPut, `{"Name":"Porter","Version":1}`), id("1")))
+
+	resp := beers.UpdateBeer(newRequest(http.MethodPut, `{"Name":"Stout","Version":1}`), id("1"))
+	expectError(t, resp, http.StatusConflict)
+	if current := resp.Data.(*models.Beer); current.Name != "Porter" || current.Version != 2 {
+		t.Fatalf("conflict should return the current beer, got %+v", current)
+	}
+}
+
+func TestPatchBeerVersion(t *testing.T) {
+	beers := newBeerController(t)
+
+	expectError(t, beers.PatchBeer(newRequest(http.MethodPatch, `{"Style":"Pils"}`, mergePatch...), id("1")), http.StatusPreconditionRequired)
+
+	resp := beers.PatchBeer(newRequest(http.MethodPatch, `{"Style":"Pils"}`, "Content-Type", patch.MergePatchType, "If-Match", `W/"1"`), id("1"))
+	expectSuccess(t, resp)
+	expectETag(t, resp, "2")
+	if beer := resp.Data.(*models.Beer); beer.Style != "Pils" || beer.Brewery != "Prazdroj" || beer.Version != 2 {
+		t.Fatalf("unexpected beer after patch: %+v", beer)
+	}
+
+	expectError(t, beers.PatchBeer(newRequest(http.MethodPatch, `{"Style":"Lager","Version":1}`, mergePatch...), id("1")), http.StatusConflict)
+	expectSuccess(t, beers.PatchBeer(newRequest(http.MethodPatch, `{"Style":"Lager","Version":2}`, mergePatch...), id("1")))
+}
+
+func TestPatchBeerJSONPatchTestVersion(t *testing.T) {
+	beers := newBeerController(t)
+
+	expectError(t, beers.PatchBeer(newRequest(http.MethodPatch,
+		`[{"op":"replace","path":"/Style","value":"Pils"}]`, jsonPatch...), id("1")), http.StatusPreconditionRequired)
+	expectError(t, beers.PatchBeer(newRequest(http.MethodPatch,
+		`[{"op":"test","path":"/Version","value":2},{"op":"replace","path":"/Style","value":"Pils"}]`, jsonPatch...), id("1")), 0)
+
+	resp := beers.PatchBeer(newRequest(http.MethodPatch,
+		`[{"op":"test","path":"/Version","value":1},{"op":"replace","path":"/Style","value":"Pils"}]`, jsonPatch...), id("1"))
+	expectSuccess(t, resp)
+	if beer := resp.Data.(*models.Beer); beer.Style != "Pils" || beer.Version != 2 {
+		t.Fatalf("unexpected beer after patch: %+v", beer)
+	}
+}
+
+func TestPatchBeerMediaType(t *testing.T) {
+	beers := newBeerController(t)
+
+	expectError(t, beers.PatchBeer(newRequest(http.MethodPatch, `{"Style":"Pils","Version":1}`), id("1")), http.StatusUnsupportedMediaType)
+	expectError(t, beers.PatchBeer(newRequest(http.MethodPatch, `{"Style":"Pils","Version":1}`, "Content-Type", ""), id("1")), http.StatusUnsupportedMediaType)
+}
+
+func TestDeleteAndRestoreBeer(t *testing.T) {
+	beers := newBeerController(t)
+
+	expectSuccess(t, beers.DeleteBeer(newRequest(http.MethodDelete, ""), id("1")))
+	expectError(t, beers.ShowBeer(newRequest(http.MethodGet, ""), id("1")), 0)
+	expectError(t, beers.DeleteBeer(newRequest(http.MethodDelete, ""), id("1")), 0)
+
+	resp := beers.GetTrashedBeers(newRequest(http.MethodGet, ""), nil)
+	expectSuccess(t, resp)
+	if trash := resp.Data.([]models.Beer); len(trash) != 1 || trash[0].ID != 1 {
+		t.Fatalf("expected beer 1 in trash, got %+v", trash)
+	}
+
+	resp = beers.RestoreBeer(newRequest(http.MethodPost, ""), id("1"))
+	expectSuccess(t, resp)
+	if beer := resp.Data.(*models.Beer); beer.DeletedAt.Valid || beer.Version != 2 {
+		t.Fatalf("unexpected beer after restore: %+v", beer)
+	}
+	expectSuccess(t, beers.ShowBeer(newRequest(http.MethodGet, ""), id("1")))
+	expectError(t, beers.RestoreBeer(newRequest(http.MethodPost, ""), id("1")), 0)
+
+	resp = beers.GetTrashedBeers(newRequest(http.MethodGet, ""), nil)
+	if trash := resp.Data.([]models.Beer); len(trash) != 0 {
+		t.Fatalf("expected empty trash, got %+v", trash)
+	}
+}
+
+func TestForceDeleteBeer(t *testing.T) {
+	beers := newBeerController(t)
+	expectSuccess(t, beers.DeleteBeer(newRequest(http.MethodDelete, ""), id("1")))
+
+	expectSuccess(t, beers.ForceDeleteBeer(newRequest(http.MethodDelete, ""), id("1")))
+	expectError(t, beers.RestoreBeer(newRequest(http.MethodPost, ""), id("1")), 0)
+	expectError(t, beers.ForceDeleteBeer(newRequest(http.MethodDelete, ""), id("1")), 0)
+	expectError(t, beers.ForceDeleteBeer(newRequest(http.MethodDelete, ""), id("x")), 0)
+}
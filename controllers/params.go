@@ -0,0 +1,62 @@
+// Package controllers содержит вспомогательные функции HTTP-обработчиков.
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"server/request"
+	"server/types"
+	"strconv"
+	"strings"
+)
+
+// parseID разбирает параметр маршрута id.
+// Если параметр отсутствует или некорректен, возвращает 0 и ответ с ошибкой.
+func parseID(r *request.Request, params map[string]string) (uint, types.JsonResponse) {
+	idStr, ok := params["id"]
+	if !ok {
+		return 0, types.JsonResponse{Status: "error", Message: r.T("request.id_required")}
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil || id == 0 {
+		return 0, types.JsonResponse{Status: "error", Message: r.T("request.invalid_id")}
+	}
+	return uint(id), types.JsonResponse{}
+}
+
+// expectedVersion возвращает версию записи, которую клиент ожидает изменить: из заголовка
+// If-Match ("3" или W/"3") или, если заголовка нет, bodyVersion из тела запроса.
+// Без версии изменение отклоняется с кодом 428: иначе запрос молча перезаписал бы
+// чужие изменения.
+func expectedVersion(r *request.Request, bodyVersion uint) (uint, types.JsonResponse) {
+	if header := r.Header("If-Match", ""); header != "" {
+		value := strings.Trim(strings.TrimPrefix(strings.TrimSpace(header), "W/"), `"`)
+		version, err := strconv.ParseUint(value, 10, 0)
+		if err != nil || version == 0 {
+			return 0, types.JsonResponse{Status: "error", Message: r.T("request.invalid_version"), Code: http.StatusBadRequest}
+		}
+		return uint(version), types.JsonResponse{}
+	}
+	if bodyVersion == 0 {
+		return 0, types.JsonResponse{Status: "error", Message: r.T("request.version_required"), Code: http.StatusPreconditionRequired}
+	}
+	return bodyVersion, types.JsonResponse{}
+}
+
+// etag возвращает заголовок ETag со слабым валидатором W/"<версия>" записи:
+// клиент передаёт его в If-Match при изменении (см. expectedVersion).
+func etag(version uint) http.Header {
+	header := http.Header{}
+	header.Set("ETag", `W/"`+strconv.FormatUint(uint64(version), 10)+`"`)
+	return header
+}
+
+// patchError преобразует ошибку применения патча в ответ: неподдерживаемый
+// Content-Type — 415, некорректный патч — ошибка с описанием.
+func patchError(r *request.Request, err error) types.JsonResponse {
+	if errors.Is(err, request.ErrUnsupportedPatchType) {
+		return types.JsonResponse{Status: "error", Message: r.T("request.unsupported_patch_type"), Code: http.StatusUnsupportedMediaType}
+	}
+	return types.JsonResponse{Status: "error", Message: r.T("request.invalid_patch", err)}
+}
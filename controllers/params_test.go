@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"server/request"
+	"testing"
+)
+
+func TestExpectedVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifMatch     string
+		bodyVersion uint
+		want        uint
+		code        int
+	}{
+		{"strong validator", `"3"`, 0, 3, 0},
+		{"weak validator", `W/"3"`, 0, 3, 0},
+		{"unquoted version", "3", 0, 3, 0},
+		{"surrounding spaces", ` W/"3" `, 0, 3, 0},
+		{"header wins over body", `"3"`, 5, 3, 0},
+		{"version from body", "", 5, 5, 0},
+		{"no version", "", 0, 0, http.StatusPreconditionRequired},
+		{"zero version", `"0"`, 0, 0, http.StatusBadRequest},
+		{"not a number", `"abc"`, 5, 0, http.StatusBadRequest},
+		{"wildcard", "*", 5, 0, http.StatusBadRequest},
+		{"several validators", `"3", "4"`, 0, 0, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			version, resp := expectedVersion(request.InitRequest(req), tt.bodyVersion)
+			if version != tt.want || resp.Code != tt.code {
+				t.Fatalf("expectedVersion = %d (code %d), want %d (code %d)", version, resp.Code, tt.want, tt.code)
+			}
+		})
+	}
+}
+
+func TestETagMatchesIfMatch(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPut, "/", nil)
+	req.Header.Set("If-Match", etag(7).Get("ETag"))
+	if version, resp := expectedVersion(request.InitRequest(req), 0); version != 7 {
+		t.Fatalf("ETag value must be accepted by If-Match, got %d: %s", version, resp.Message)
+	}
+	if got := etag(7).Get("ETag"); got != `W/"7"` {
+		t.Fatalf("ETag = %s", got)
+	}
+}
@@ -2,58 +2,60 @@
 package controllers
 
 import (
-	"server/database"
+	"errors"
+	"net/http"
 	"server/models"
+	"server/repository"
 	"server/request"
 	"server/types"
-	"strconv"
-
-	"gorm.io/gorm"
 )
 
+// SnackController обрабатывает запросы к закускам через хранилище snacks.
+// Ответы с одной записью содержат заголовок ETag с её версией для If-Match.
+type SnackController struct {
+	snacks repository.SnackRepository
+}
+
+// NewSnackController создаёт контроллер закусок поверх хранилища snacks.
+func NewSnackController(snacks repository.SnackRepository) *SnackController {
+	return &SnackController{snacks: snacks}
+}
+
 // CreateSnack создаёт новую запись закуски на основе JSON из запроса.
 // Возвращает ошибку, если входные данные некорректны или произошла ошибка базы данных.
-func CreateSnack(r *request.Request, params map[string]string) types.JsonResponse {
+func (c *SnackController) CreateSnack(r *request.Request, params map[string]string) types.JsonResponse {
 	var snack models.Snack
 	if err := r.Json(&snack); err != nil {
-		return types.JsonResponse{Status: "error", Message: "Invalid input: " + err.Error()}
+		return types.JsonResponse{Status: "error", Message: r.T("request.invalid_input", err)}
 	}
 
-	if err := models.CreateSnack(database.DB, &snack); err != nil {
+	if err := snack.Validate(); err != nil {
+		return types.JsonResponse{Status: "error", Message: r.T("validation.failed", r.Localize(err))}
+	}
+
+	if err := c.snacks.Create(r.Context(), &snack); err != nil {
 		return types.JsonResponse{Status: "error", Message: err.Error()}
 	}
 
-	return types.JsonResponse{Status: "success", Data: snack}
+	return types.JsonResponse{Status: "success", Data: snack, Header: etag(snack.Version)}
 }
 
 // GetSnack возвращает закуску по ID, переданному в параметрах маршрута.
 // Возвращает ошибку, если ID отсутствует, некорректен или запись не найдена.
-func GetSnack(r *request.Request, params map[string]string) types.JsonResponse {
-	idStr, ok := params["id"]
-	if !ok {
-		return types.JsonResponse{Status: "error", Message: "ID parameter required"}
-	}
-
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		return types.JsonResponse{Status: "error", Message: "Invalid ID"}
+func (c *SnackController) GetSnack(r *request.Request, params map[string]string) types.JsonResponse {
+	snack, resp := c.find(r, params)
+	if snack == nil {
+		return resp
 	}
 
-	snack, err := models.GetSnackByID(database.DB, uint(id))
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return types.JsonResponse{Status: "error", Message: "Snack not found"}
-		}
-		return types.JsonResponse{Status: "error", Message: err.Error()}
-	}
-
-	return types.JsonResponse{Status: "success", Data: snack}
+	return types.JsonResponse{Status: "success", Data: snack, Header: etag(snack.Version)}
 }
 
 // GetAllSnacks возвращает список всех закусок из базы данных.
+// Параметр запроса q включает полнотекстовый поиск по названию, типу, описанию и стране.
 // В случае ошибки возвращает соответствующее сообщение.
-func GetAllSnacks(r *request.Request, params map[string]string) types.JsonResponse {
-	snacks, err := models.GetAllSnacks(database.DB)
+func (c *SnackController) GetAllSnacks(r *request.Request, params map[string]string) types.JsonResponse {
+	snacks, err := c.snacks.List(r.Context(), r.Query("q", ""))
 	if err != nil {
 		return types.JsonResponse{Status: "error", Message: err.Error()}
 	}
@@ -63,30 +65,24 @@ func GetAllSnacks(r *request.Request, params map[string]string) types.JsonRespon
 
 // UpdateSnack обновляет существующую запись закуски по ID.
 // Возвращает ошибку, если ID отсутствует, некорректен, запись не найдена или входные данные некорректны.
-func UpdateSnack(r *request.Request, params map[string]string) types.JsonResponse {
-	idStr, ok := params["id"]
-	if !ok {
-		return types.JsonResponse{Status: "error", Message: "ID parameter required"}
-	}
-
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		return types.JsonResponse{Status: "error", Message: "Invalid ID"}
-	}
-
-	snack, err := models.GetSnackByID(database.DB, uint(id))
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return types.JsonResponse{Status: "error", Message: "Snack not found"}
-		}
-		return types.JsonResponse{Status: "error", Message: err.Error()}
+// Ожидаемая версия записи передаётся заголовком If-Match или полем Version; без неё возвращается 428,
+// а если запись уже изменена другим запросом — 409.
+func (c *SnackController) UpdateSnack(r *request.Request, params map[string]string) types.JsonResponse {
+	snack, resp := c.find(r, params)
+	if snack == nil {
+		return resp
 	}
 
 	var input models.Snack
 	if err := r.Json(&input); err != nil {
-		return types.JsonResponse{Status: "error", Message: "Invalid input: " + err.Error()}
+		return types.JsonResponse{Status: "error", Message: r.T("request.invalid_input", err)}
 	}
 
+	version, resp := expectedVersion(r, input.Version)
+	if version == 0 {
+		return resp
+	}
+	snack.Version = version
 	snack.Name = input.Name
 	snack.Type = input.Type
 	snack.Description = input.Description
@@ -95,43 +91,76 @@ func UpdateSnack(r *request.Request, params map[string]string) types.JsonRespons
 	snack.Spicy = input.Spicy
 	snack.Vegetarian = input.Vegetarian
 
-	if err := models.UpdateSnack(database.DB, snack); err != nil {
-		return types.JsonResponse{Status: "error", Message: err.Error()}
+	if err := snack.Validate(); err != nil {
+		return types.JsonResponse{Status: "error", Message: r.T("validation.failed", r.Localize(err))}
 	}
 
-	return types.JsonResponse{Status: "success", Data: snack}
+	return c.save(r, snack)
 }
 
-// DeleteSnack удаляет запись закуски по ID.
-// Возвращает ошибку, если ID отсутствует, некорректен или произошла ошибка при удалении.
-func DeleteSnack(r *request.Request, params map[string]string) types.JsonResponse {
-	idStr, ok := params["id"]
-	if !ok {
-		return types.JsonResponse{Status: "error", Message: "ID parameter required"}
+// PatchSnack частично обновляет запись закуски по ID.
+// Тело запроса применяется как JSON Merge Patch (RFC 7396) или JSON Patch (RFC 6902)
+// в зависимости от Content-Type (для других типов возвращается 415), результат проходит
+// валидацию перед сохранением. Ожидаемая версия записи передаётся заголовком If-Match,
+// полем Version в merge patch или операцией test/replace пути /Version в JSON Patch.
+func (c *SnackController) PatchSnack(r *request.Request, params map[string]string) types.JsonResponse {
+	snack, resp := c.find(r, params)
+	if snack == nil {
+		return resp
 	}
 
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		return types.JsonResponse{Status: "error", Message: "Invalid ID"}
+	before := *snack
+	if err := r.Patch(snack); err != nil {
+		return patchError(r, err)
+	}
+	// Версия из патча учитывается, только если патч её задаёт или проверяет операцией test
+	var patchVersion uint
+	if r.PatchMentions("Version") {
+		patchVersion = snack.Version
 	}
+	version, resp := expectedVersion(r, patchVersion)
+	if version == 0 {
+		return resp
+	}
+	snack.Version = version
 
-	if err := models.DeleteSnack(database.DB, uint(id)); err != nil {
-		return types.JsonResponse{Status: "error", Message: err.Error()}
+	// Служебные поля не изменяются через патч
+	snack.ID = before.ID
+	snack.CreatedAt = before.CreatedAt
+	snack.UpdatedAt = before.UpdatedAt
+	snack.DeletedAt = before.DeletedAt
+
+	if err := snack.Validate(); err != nil {
+		return types.JsonResponse{Status: "error", Message: r.T("validation.failed", r.Localize(err))}
+	}
+
+	return c.save(r, snack)
+}
+
+// DeleteSnack удаляет запись закуски по ID.
+// Возвращает ошибку, если ID отсутствует, некорректен, запись не найдена или произошла ошибка при удалении.
+func (c *SnackController) DeleteSnack(r *request.Request, params map[string]string) types.JsonResponse {
+	snack, resp := c.find(r, params)
+	if snack == nil {
+		return resp
+	}
+
+	if err := c.snacks.Delete(r.Context(), snack.ID); err != nil {
+		return c.error(r, err)
 	}
 
-	return types.JsonResponse{Status: "success", Message: "Snack deleted"}
+	return types.JsonResponse{Status: "success", Message: r.T("snack.deleted")}
 }
 
 // GetRandomSnack возвращает случайную закуску из базы данных.
 // Если закусок нет, возвращает соответствующее сообщение об ошибке.
-func GetRandomSnack(r *request.Request, params map[string]string) types.JsonResponse {
-	var snack models.Snack
-	err := database.DB.Order("RAND()").First(&snack).Error
+func (c *SnackController) GetRandomSnack(r *request.Request, params map[string]string) types.JsonResponse {
+	snack, err := c.snacks.Random(r.Context())
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
+		if errors.Is(err, repository.ErrNotFound) {
 			return types.JsonResponse{
 				Status:  "error",
-				Message: "No snacks found",
+				Message: r.T("snack.none"),
 			}
 		}
 		return types.JsonResponse{
@@ -145,3 +174,81 @@ func GetRandomSnack(r *request.Request, params map[string]string) types.JsonResp
 		Data:   snack,
 	}
 }
+
+// GetTrashedSnacks возвращает записи закусок из корзины — мягко удалённые, но ещё не удалённые окончательно.
+func (c *SnackController) GetTrashedSnacks(r *request.Request, params map[string]string) types.JsonResponse {
+	snacks, err := c.snacks.Trash(r.Context())
+	if err != nil {
+		return types.JsonResponse{Status: "error", Message: err.Error()}
+	}
+
+	return types.JsonResponse{Status: "success", Data: snacks}
+}
+
+// RestoreSnack восстанавливает запись закуски из корзины по ID.
+// Возвращает ошибку, если ID отсутствует, некорректен или записи нет в корзине.
+func (c *SnackController) RestoreSnack(r *request.Request, params map[string]string) types.JsonResponse {
+	id, resp := parseID(r, params)
+	if id == 0 {
+		return resp
+	}
+
+	snack, err := c.snacks.Restore(r.Context(), id)
+	if err != nil {
+		return c.error(r, err)
+	}
+
+	return types.JsonResponse{Status: "success", Data: snack, Header: etag(snack.Version)}
+}
+
+// ForceDeleteSnack окончательно удаляет запись закуски по ID, в том числе из корзины.
+// Возвращает ошибку, если ID отсутствует, некорректен или запись не найдена.
+func (c *SnackController) ForceDeleteSnack(r *request.Request, params map[string]string) types.JsonResponse {
+	id, resp := parseID(r, params)
+	if id == 0 {
+		return resp
+	}
+
+	if err := c.snacks.ForceDelete(r.Context(), id); err != nil {
+		return c.error(r, err)
+	}
+
+	return types.JsonResponse{Status: "success", Message: r.T("snack.purged")}
+}
+
+// find загружает закуску по параметру маршрута id.
+// Если загрузить запись не удалось, возвращает nil и ответ с ошибкой.
+func (c *SnackController) find(r *request.Request, params map[string]string) (*models.Snack, types.JsonResponse) {
+	id, resp := parseID(r, params)
+	if id == 0 {
+		return nil, resp
+	}
+
+	snack, err := c.snacks.Find(r.Context(), id)
+	if err != nil {
+		return nil, c.error(r, err)
+	}
+	return snack, types.JsonResponse{}
+}
+
+// save сохраняет изменённую закуску и возвращает её в ответе.
+func (c *SnackController) save(r *request.Request, snack *models.Snack) types.JsonResponse {
+	if err := c.snacks.Update(r.Context(), snack); err != nil {
+		return c.error(r, err)
+	}
+
+	return types.JsonResponse{Status: "success", Data: snack, Header: etag(snack.Version)}
+}
+
+// error преобразует ошибку хранилища в ответ. При конфликте версий
+// возвращается 409 с текущим состоянием записи.
+func (c *SnackController) error(r *request.Request, err error) types.JsonResponse {
+	var conflict *repository.ConflictError
+	if errors.As(err, &conflict) {
+		return types.JsonResponse{Status: "error", Message: r.T("snack.conflict"), Data: conflict.Current, Code: http.StatusConflict}
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return types.JsonResponse{Status: "error", Message: r.T("snack.not_found")}
+	}
+	return types.JsonResponse{Status: "error", Message: err.Error()}
+}
@@ -0,0 +1,68 @@
+package controllers_test
+
+import (
+	"net/http"
+	"server/app"
+	"server/controllers"
+	"server/models"
+	"testing"
+)
+
+// newSnackController создаёт контроллер закусок с хранилищем в памяти и одной записью версии 1.
+func newSnackController(t *testing.T) *controllers.SnackController {
+	t.Helper()
+	snacks := controllers.NewSnackController(app.NewInMemory().Snacks)
+	expectSuccess(t, snacks.CreateSnack(newRequest(http.MethodPost, `{"Name":"Pretzel","Type":"Bakery","Country":"Germany"}`), nil))
+	return snacks
+}
+
+func TestCreateSnack(t *testing.T) {
+	snacks := newSnackController(t)
+
+	resp := snacks.GetSnack(newRequest(http.MethodGet, ""), id("1"))
+	expectSuccess(t, resp)
+	if snack := resp.Data.(*models.Snack); snack.Name != "Pretzel" || snack.Version != 1 {
+		t.Fatalf("unexpected snack: %+v", snack)
+	}
+
+	expectError(t, snacks.CreateSnack(newRequest(http.MethodPost, `{"Calories":-1}`), nil), 0)
+}
+
+func TestUpdateSnackConflict(t *testing.T) {
+	snacks := newSnackController(t)
+
+	expectError(t, snacks.UpdateSnack(newRequest(http.MethodPut, `{"Name":"Chips"}`), id("1")), http.StatusPreconditionRequired)
+	expectSuccess(t, snacks.UpdateSnack(newRequest(http.MethodPut, `{"Name":"Chips","Version":1}`), id("1")))
+
+	resp := snacks.UpdateSnack(newRequest(http.MethodPut, `{"Name":"Nachos"}`, "If-Match", `"1"`), id("1"))
+	expectError(t, resp, http.StatusConflict)
+	if current := resp.Data.(*models.Snack); current.Name != "Chips" || current.Version != 2 {
+		t.Fatalf("conflict should return the current snack, got %+v", current)
+	}
+
+	expectError(t, snacks.PatchSnack(newRequest(http.MethodPatch, `{"Spicy":true}`, mergePatch...), id("1")), http.StatusPreconditionRequired)
+	expectSuccess(t, snacks.PatchSnack(newRequest(http.MethodPatch, `{"Spicy":true,"Version":2}`, mergePatch...), id("1")))
+}
+
+func TestDeleteAndRestoreSnack(t *testing.T) {
+	snacks := newSnackController(t)
+
+	expectSuccess(t, snacks.DeleteSnack(newRequest(http.MethodDelete, ""), id("1")))
+	expectError(t, snacks.GetSnack(newRequest(http.MethodGet, ""), id("1")), 0)
+
+	resp := snacks.GetTrashedSnacks(newRequest(http.MethodGet, ""), nil)
+	expectSuccess(t, resp)
+	if trash := resp.Data.([]models.Snack); len(trash) != 1 || trash[0].ID != 1 {
+		t.Fatalf("expected snack 1 in trash, got %+v", trash)
+	}
+
+	resp = snacks.RestoreSnack(newRequest(http.MethodPost, ""), id("1"))
+	expectSuccess(t, resp)
+	if snack := resp.Data.(*models.Snack); snack.DeletedAt.Valid || snack.Version != 2 {
+		t.Fatalf("unexpected snack after restore: %+v", snack)
+	}
+	expectError(t, snacks.RestoreSnack(newRequest(http.MethodPost, ""), id("1")), 0)
+
+	expectSuccess(t, snacks.ForceDeleteSnack(newRequest(http.MethodDelete, ""), id("1")))
+	expectError(t, snacks.GetSnack(newRequest(http.MethodGet, ""), id("1")), 0)
+}
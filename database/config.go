@@ -0,0 +1,147 @@
+// Package database отвечает за инициализацию и настройку подключения к базе данных.
+package database
+
+import (
+	"fmt"
+	"net"
+	"server/config"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// Config содержит параметры подключения к базе данных.
+type Config struct {
+	Driver   string // Драйвер: "mysql", "postgres" или "sqlite"
+	DSN      string // Полная строка подключения; если задана, заменяет отдельные параметры ниже
+	Host     string // Адрес сервера
+	Port     string // Порт сервера
+	User     string // Имя пользователя
+	Password string // Пароль
+	Name     string // Имя базы данных; для SQLite — путь к файлу
+	Params   string // Дополнительные параметры подключения в формате URL-запроса
+
+	MaxIdleConns    int           // Максимальное количество неиспользуемых соединений
+	MaxOpenConns    int           // Максимальное количество открытых соединений
+	ConnMaxLifetime time.Duration // Максимальное время жизни соединения
+	ConnMaxIdleTime time.Duration // Максимальное время простоя соединения
+
+	LogLevel      logger.LogLevel // Уровень логирования запросов
+	SlowThreshold time.Duration   // Порог, после которого запрос считается медленным
+
+	ConnectAttempts   int           // Число попыток подключения при запуске; 0 — без ограничения
+	ConnectBackoff    time.Duration // Пауза перед второй попыткой, далее удваивается
+	ConnectMaxBackoff time.Duration // Наибольшая пауза между попытками
+
+	// Replicas — реплики для чтения: адреса host[:port] с теми же учётными данными,
+	// что и у основной базы, для SQLite — пути к файлам, а при заданном DSN — полные строки подключения.
+	Replicas []string
+}
+
+// driverDefaults — значения параметров подключения по умолчанию для каждого драйвера.
+var driverDefaults = map[string]Config{
+	"mysql":    {Port: "3306", User: "root", Name: "servergo", Params: "charset=utf8mb4&parseTime=True&loc=Local"},
+	"postgres": {Port: "5432", User: "postgres", Name: "servergo", Params: "sslmode=disable"},
+	"sqlite":   {Name: "servergo.db", Params: "_foreign_keys=on&_busy_timeout=5000"},
+}
+
+// LoadConfig читает параметры подключения из переменных окружения или файла конфигурации:
+// DB_DRIVER (mysql, postgres, sqlite), DB_DSN или DB_HOST, DB_PORT, DB_USER, DB_PASSWORD (или DB_PASSWORD_FILE), DB_NAME, DB_PARAMS;
+// DB_MAX_IDLE_CONNS, DB_MAX_OPEN_CONNS, DB_CONN_MAX_LIFETIME, DB_CONN_MAX_IDLE_TIME;
+// DB_LOG_LEVEL (silent, error, warn, info), DB_SLOW_THRESHOLD, список реплик для чтения DB_REPLICAS;
+// повторные попытки подключения DB_CONNECT_ATTEMPTS (по умолчанию без ограничения), DB_CONNECT_BACKOFF
+// и DB_CONNECT_MAX_BACKOFF.
+// Значения по умолчанию для порта, пользователя, имени базы и параметров зависят от драйвера.
+func LoadConfig() (Config, error) {
+	driver := config.String("DB_DRIVER", "mysql")
+	if _, err := dialectFor(driver); err != nil {
+		return Config{}, err
+	}
+	defaults := driverDefaults[driver]
+
+	password, err := config.Secret("DB_PASSWORD", "")
+	if err != nil {
+		return Config{}, err
+	}
+	dsn, err := config.Secret("DB_DSN", "")
+	if err != nil {
+		return Config{}, err
+	}
+	level, err := parseLogLevel(config.String("DB_LOG_LEVEL", "info"))
+	if err != nil {
+		return Config{}, err
+	}
+
+	return Config{
+		Driver:   driver,
+		DSN:      dsn,
+		Host:     config.String("DB_HOST", "localhost"),
+		Port:     config.String("DB_PORT", defaults.Port),
+		User:     config.String("DB_USER", defaults.User),
+		Password: password,
+		Name:     config.String("DB_NAME", defaults.Name),
+		Params:   config.String("DB_PARAMS", defaults.Params),
+
+		MaxIdleConns:    config.Int("DB_MAX_IDLE_CONNS", 10),
+		MaxOpenConns:    config.Int("DB_MAX_OPEN_CONNS", 100),
+		ConnMaxLifetime: config.Duration("DB_CONN_MAX_LIFETIME", time.Hour),
+		ConnMaxIdleTime: config.Duration("DB_CONN_MAX_IDLE_TIME", 0),
+
+		LogLevel:      level,
+		SlowThreshold: config.Duration("DB_SLOW_THRESHOLD", 200*time.Millisecond),
+
+		ConnectAttempts:   config.Int("DB_CONNECT_ATTEMPTS", 0),
+		ConnectBackoff:    config.Duration("DB_CONNECT_BACKOFF", time.Second),
+		ConnectMaxBackoff: config.Duration("DB_CONNECT_MAX_BACKOFF", 30*time.Second),
+
+		Replicas: config.List("DB_REPLICAS"),
+	}, nil
+}
+
+// replica возвращает параметры подключения к реплике addr (см. Config.Replicas).
+func (c Config) replica(addr string) Config {
+	r := c
+	r.Replicas = nil
+	switch {
+	case c.DSN != "":
+		r.DSN = addr
+	case c.Driver == "sqlite":
+		r.Name = addr
+	default:
+		if host, port, err := net.SplitHostPort(addr); err == nil {
+			r.Host, r.Port = host, port
+		} else {
+			r.Host = addr
+		}
+	}
+	return r
+}
+
+// Redacted возвращает строку подключения со скрытым паролем для вывода в лог.
+func (c Config) Redacted() string {
+	d, err := dialectFor(c.Driver)
+	if err != nil {
+		return "<invalid dsn>"
+	}
+	dsn, err := d.DSN(c)
+	if err != nil {
+		return "<invalid dsn>"
+	}
+	return d.Redact(dsn)
+}
+
+// parseLogLevel разбирает уровень логирования GORM.
+func parseLogLevel(level string) (logger.LogLevel, error) {
+	switch strings.ToLower(level) {
+	case "silent":
+		return logger.Silent, nil
+	case "error":
+		return logger.Error, nil
+	case "warn":
+		return logger.Warn, nil
+	case "info":
+		return logger.Info, nil
+	}
+	return 0, fmt.Errorf("DB_LOG_LEVEL: unknown level %q", level)
+}
@@ -0,0 +1,120 @@
+// Package database отвечает за инициализацию и настройку подключения к базе данных.
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// pingTimeout — время ожидания ответа базы данных при проверке соединения.
+const pingTimeout = 2 * time.Second
+
+var (
+	// healthy — результат последней проверки соединения с основной базой.
+	healthy atomic.Bool
+	// healthWatch гарантирует единственный запуск WatchHealth.
+	healthWatch sync.Once
+)
+
+// ErrNotConnected возвращается Ping, пока подключение к базе данных не установлено.
+var ErrNotConnected = errors.New("database is not connected")
+
+// Connect подключается к базе данных через Init, повторяя неудачные попытки с экспоненциально
+// растущей паузой и случайным разбросом: не более cfg.ConnectAttempts раз (0 — без ограничения)
+// или пока не будет отменён ctx. Позволяет запускать сервер раньше, чем станет доступна СУБД.
+func Connect(ctx context.Context, cfg Config) error {
+	for attempt := 1; ; attempt++ {
+		err := Init(cfg)
+		if err == nil {
+			healthy.Store(true)
+			return nil
+		}
+		if cfg.ConnectAttempts > 0 && attempt >= cfg.ConnectAttempts {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		delay := backoff(attempt, cfg.ConnectBackoff, cfg.ConnectMaxBackoff)
+		log.Printf("database: connection attempt %d failed: %v; retrying in %s", attempt, err, delay.Round(time.Millisecond))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// backoff возвращает паузу после попытки attempt: base, удвоенную attempt-1 раз
+// и ограниченную max, со случайным разбросом в пределах её второй половины,
+// чтобы одновременно запущенные экземпляры не обращались к СУБД синхронно.
+func backoff(attempt int, base, max time.Duration) time.Duration {
+	if base <= 0 {
+		base = time.Second
+	}
+	delay := base
+	for i := 1; i < attempt && (max <= 0 || delay < max); i++ {
+		delay *= 2
+	}
+	if max > 0 && delay > max {
+		delay = max
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// Ping проверяет соединение с основной базой данных.
+// Возвращает ErrNotConnected, если подключение ещё не установлено.
+func Ping(ctx context.Context) error {
+	if DB == nil {
+		return ErrNotConnected
+	}
+	return ping(ctx, DB)
+}
+
+// Healthy сообщает результат последней проверки соединения с основной базой данных:
+// после успешного Connect и далее по результатам WatchHealth.
+func Healthy() bool {
+	return healthy.Load()
+}
+
+// WatchHealth запускает фоновую проверку соединения с основной базой данных с периодом interval
+// и логирует потерю и восстановление соединения. Пул соединений database/sql переподключается
+// сам, поэтому после восстановления СУБД запросы снова выполняются без перезапуска сервера.
+// Повторные вызовы ничего не делают.
+func WatchHealth(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	healthWatch.Do(func() {
+		go func() {
+			for range time.Tick(interval) {
+				err := Ping(context.Background())
+				if ok := err == nil; healthy.Swap(ok) != ok {
+					if ok {
+						log.Println("database: connection restored")
+					} else {
+						log.Printf("database: connection lost: %v", err)
+					}
+				}
+			}
+		}()
+	})
+}
+
+// ping проверяет соединение с базой db.
+func ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
@@ -0,0 +1,38 @@
+// Package dbtest подключает тесты к отдельной базе данных SQLite в памяти.
+package dbtest
+
+import (
+	"net/url"
+	"server/database"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open инициализирует database.DB базой SQLite в памяти, отдельной для теста t,
+// и закрывает её по завершении теста. База живёт, пока открыто её единственное
+// соединение, поэтому пул ограничен одним соединением: код, который во время
+// транзакции обращается к базе в обход неё, в тесте зависнет.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+	err := database.Init(database.Config{
+		Driver:       "sqlite",
+		DSN:          "file:" + url.PathEscape(t.Name()) + "?mode=memory&cache=shared&_foreign_keys=on",
+		MaxIdleConns: 1,
+		MaxOpenConns: 1,
+		LogLevel:     logger.Silent,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := database.DB
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		database.DB = nil
+	})
+	return db
+}
@@ -0,0 +1,81 @@
+// Package database отвечает за инициализацию и настройку подключения к базе данных.
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Dialect скрывает различия между поддерживаемыми СУБД.
+type Dialect interface {
+	// Name возвращает имя драйвера: "mysql", "postgres" или "sqlite".
+	Name() string
+	// DSN собирает строку подключения из параметров cfg.
+	DSN(cfg Config) (string, error)
+	// Redact возвращает строку подключения со скрытым паролем.
+	Redact(dsn string) string
+	// Open возвращает диалектор GORM для строки подключения dsn.
+	Open(dsn string) gorm.Dialector
+	// RandomOrder возвращает выражение ORDER BY для случайного порядка строк.
+	RandomOrder() string
+	// Search добавляет к запросу условие полнотекстового поиска query по столбцам columns.
+	Search(db *gorm.DB, query string, columns ...string) *gorm.DB
+	// EnsureSearchIndex создаёт индекс полнотекстового поиска по столбцам таблицы, если он нужен и отсутствует.
+	EnsureSearchIndex(db *gorm.DB, table string, columns ...string) error
+	// DropSearchIndex удаляет индекс, созданный EnsureSearchIndex.
+	DropSearchIndex(db *gorm.DB, table string) error
+	// Lock захватывает именованную блокировку, ожидая её не дольше timeout.
+	// conn должен быть закреплённым соединением (gorm.DB.Connection), тем же, что и в Unlock.
+	Lock(conn *gorm.DB, name string, timeout time.Duration) error
+	// Unlock освобождает блокировку, захваченную Lock.
+	Unlock(conn *gorm.DB, name string) error
+}
+
+// ErrLockTimeout возвращается Dialect.Lock, если блокировку не удалось получить за отведённое время.
+var ErrLockTimeout = errors.New("lock timeout")
+
+// lockRetryInterval — интервал повторных попыток захвата блокировки.
+const lockRetryInterval = 500 * time.Millisecond
+
+// dialects — поддерживаемые драйверы по имени.
+var dialects = map[string]Dialect{
+	"mysql":    mysqlDialect{},
+	"postgres": postgresDialect{},
+	"sqlite":   sqliteDialect{},
+}
+
+// current — диалект подключения DB.
+var current Dialect = mysqlDialect{}
+
+// dialectFor возвращает диалект драйвера name.
+func dialectFor(name string) (Dialect, error) {
+	d, ok := dialects[name]
+	if !ok {
+		return nil, fmt.Errorf("DB_DRIVER: unsupported driver %q", name)
+	}
+	return d, nil
+}
+
+// Current возвращает диалект текущего подключения к базе данных.
+func Current() Dialect {
+	return current
+}
+
+// RandomOrder возвращает выражение ORDER BY для случайного порядка строк в текущей СУБД.
+func RandomOrder() string {
+	return current.RandomOrder()
+}
+
+// Search добавляет к запросу условие полнотекстового поиска query по столбцам columns
+// средствами текущей СУБД.
+func Search(db *gorm.DB, query string, columns ...string) *gorm.DB {
+	return current.Search(db, query, columns...)
+}
+
+// searchIndexName возвращает имя индекса полнотекстового поиска таблицы.
+func searchIndexName(table string) string {
+	return "idx_" + table + "_search"
+}
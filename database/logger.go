@@ -0,0 +1,107 @@
+// Package database отвечает за инициализацию и настройку подключения к базе данных.
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"server/request"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// requestLogger — логгер GORM, добавляющий к каждой строке идентификатор запроса
+// из контекста, переданного через DB.WithContext.
+type requestLogger struct {
+	logger.Interface
+	writer logger.Writer
+	config logger.Config
+}
+
+// newLogger создаёт логгер GORM с указанным уровнем логирования и порогом медленных запросов.
+func newLogger(level logger.LogLevel, slowThreshold time.Duration) logger.Interface {
+	config := logger.Config{
+		SlowThreshold: slowThreshold,
+		LogLevel:      level,
+		Colorful:      true,
+	}
+	writer := log.New(os.Stdout, "\r\n", log.LstdFlags)
+	return &requestLogger{Interface: logger.New(writer, config), writer: writer, config: config}
+}
+
+// LogMode возвращает копию логгера с новым уровнем логирования.
+func (l *requestLogger) LogMode(level logger.LogLevel) logger.Interface {
+	config := l.config
+	config.LogLevel = level
+	return &requestLogger{Interface: logger.New(l.writer, config), writer: l.writer, config: config}
+}
+
+// Info логирует информационное сообщение.
+func (l *requestLogger) Info(ctx context.Context, msg string, data ...any) {
+	l.forContext(ctx).Info(ctx, msg, data...)
+}
+
+// Warn логирует предупреждение.
+func (l *requestLogger) Warn(ctx context.Context, msg string, data ...any) {
+	l.forContext(ctx).Warn(ctx, msg, data...)
+}
+
+// Error логирует ошибку.
+func (l *requestLogger) Error(ctx context.Context, msg string, data ...any) {
+	l.forContext(ctx).Error(ctx, msg, data...)
+}
+
+// Trace логирует выполненный SQL-запрос. Запросы, прерванные отменой контекста,
+// логируются отдельной строкой с причиной: клиент отключился или истекло время запроса.
+func (l *requestLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if reason := cancelReason(err); reason != "" {
+		if l.config.LogLevel >= logger.Warn {
+			sql, rows := fc()
+			l.writerFor(ctx).Printf("%s after %.3fms [rows:%d] %s", reason, float64(time.Since(begin).Microseconds())/1000, rows, sql)
+		}
+		return
+	}
+	l.forContext(ctx).Trace(ctx, begin, fc, err)
+}
+
+// cancelReason возвращает описание отмены запроса, если err вызвана отменой контекста.
+func cancelReason(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "query cancelled: client disconnected"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "query cancelled: request timed out"
+	}
+	return ""
+}
+
+// forContext возвращает логгер, добавляющий идентификатор запроса из ctx,
+// или базовый логгер, если идентификатора нет.
+func (l *requestLogger) forContext(ctx context.Context) logger.Interface {
+	if request.IDFromContext(ctx) == "" {
+		return l.Interface
+	}
+	return logger.New(l.writerFor(ctx), l.config)
+}
+
+// writerFor возвращает writer, добавляющий к строкам идентификатор запроса из ctx.
+func (l *requestLogger) writerFor(ctx context.Context) logger.Writer {
+	id := request.IDFromContext(ctx)
+	if id == "" {
+		return l.writer
+	}
+	return prefixWriter{writer: l.writer, prefix: "[request_id=" + id + "] "}
+}
+
+// prefixWriter добавляет префикс к каждой строке лога.
+type prefixWriter struct {
+	writer logger.Writer
+	prefix string
+}
+
+// Printf записывает строку лога с префиксом.
+func (w prefixWriter) Printf(format string, args ...any) {
+	w.writer.Printf(w.prefix+format, args...)
+}
@@ -2,45 +2,108 @@
 package database
 
 import (
-	"gorm.io/driver/mysql"
+	"context"
+	"errors"
+	"log"
+	"strings"
+
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
-	"time"
 )
 
 // DB — глобальный экземпляр подключения к базе данных.
 var DB *gorm.DB
 
-// Init инициализирует подключение к базе данных MySQL с заданными параметрами,
-// настраивает пул соединений и логирование.
-// Возвращает ошибку в случае неудачи подключения.
-func Init() error {
-	dsn := "root:123@tcp(localhost:3306)/servergo?charset=utf8mb4&parseTime=True&loc=Local"
-
-	db, err := gorm.Open(mysql.New(mysql.Config{
-		DSN:                       dsn,
-		DefaultStringSize:         256,
-		DisableDatetimePrecision:  true,
-		DontSupportRenameIndex:    true,
-		DontSupportRenameColumn:   true,
-		SkipInitializeWithVersion: false,
-	}), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-	})
+// Init инициализирует подключение к базе данных с параметрами cfg через драйвер cfg.Driver,
+// настраивает пул соединений и логирование. Если заданы cfg.Replicas, открывает
+// подключения к репликам для чтения (см. ReadFromReplica).
+// Возвращает ошибку в случае неудачи подключения; пароль в ошибках и логах скрывается.
+func Init(cfg Config) error {
+	d, err := dialectFor(cfg.Driver)
 	if err != nil {
 		return err
 	}
-
-	sqlDB, err := db.DB()
+	log.Printf("database: connecting to %s %s", d.Name(), cfg.Redacted())
+	db, err := open(d, cfg)
 	if err != nil {
 		return err
 	}
 
-	// Настройка пула соединений с базой данных
-	sqlDB.SetMaxIdleConns(10)           // Максимальное количество неиспользуемых соединений
-	sqlDB.SetMaxOpenConns(100)          // Максимальное количество открытых соединений
-	sqlDB.SetConnMaxLifetime(time.Hour) // Максимальное время жизни соединения
+	var pool []*replica
+	for _, addr := range cfg.Replicas {
+		rc := cfg.replica(addr)
+		dsn, err := d.DSN(rc)
+		if err != nil {
+			return err
+		}
+		r := &replica{name: d.Redact(dsn), connect: func() (*gorm.DB, error) { return open(d, rc) }}
+		log.Printf("database: connecting to read replica %s", r.name)
+		// Недоступная при запуске реплика не мешает старту: к ней переподключится CheckReplicas
+		if err := r.check(context.Background()); err != nil {
+			log.Printf("database: replica %s is unavailable, excluded from reads: %v", r.name, err)
+		}
+		pool = append(pool, r)
+	}
 
 	DB = db
+	current = d
+	replicas.set(pool)
 	return nil
 }
+
+// open подключается к базе данных с параметрами cfg и настраивает пул соединений.
+func open(d Dialect, cfg Config) (*gorm.DB, error) {
+	dsn, err := d.DSN(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(d.Open(dsn), &gorm.Config{
+		Logger: newLogger(cfg.LogLevel, cfg.SlowThreshold),
+	})
+	if err != nil {
+		return nil, redactError(err, cfg.Password)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	// Настройка пула соединений с базой данных
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	return db, nil
+}
+
+// redactError скрывает пароль, если он попал в текст ошибки драйвера.
+func redactError(err error, password string) error {
+	if password == "" || !strings.Contains(err.Error(), password) {
+		return err
+	}
+	return errors.New(strings.ReplaceAll(err.Error(), password, "***"))
+}
+
+// Conn возвращает подключение к базе данных, привязанное к контексту ctx.
+// Если ctx содержит транзакцию (см. Transaction), возвращается она; если ctx помечен
+// ReadFromReplica — исправная реплика или, при их отсутствии, основная база.
+// Контекст передаётся в логгер, чтобы строки лога содержали идентификатор запроса.
+func Conn(ctx context.Context) *gorm.DB {
+	return ConnOr(ctx, DB)
+}
+
+// ConnOr работает как Conn, но вместо DB использует подключение db.
+// Используется хранилищами, которым подключение передано явно; реплики
+// выбираются, только если db — основное подключение DB.
+func ConnOr(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	if db == DB && readsFromReplica(ctx) {
+		if r := replicas.pick(); r != nil {
+			return r.WithContext(ctx)
+		}
+	}
+	return db.WithContext(ctx)
+}
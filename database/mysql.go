@@ -0,0 +1,113 @@
+// Package database отвечает за инициализацию и настройку подключения к базе данных.
+package database
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// mysqlDialect — диалект MySQL.
+type mysqlDialect struct{}
+
+// Name возвращает имя драйвера.
+func (mysqlDialect) Name() string {
+	return "mysql"
+}
+
+// DSN собирает строку подключения MySQL.
+func (mysqlDialect) DSN(c Config) (string, error) {
+	if c.DSN != "" {
+		return c.DSN, nil
+	}
+
+	cfg := mysqldriver.NewConfig()
+	cfg.User = c.User
+	cfg.Passwd = c.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(c.Host, c.Port)
+	cfg.DBName = c.Name
+
+	dsn := cfg.FormatDSN()
+	if c.Params != "" {
+		dsn += "?" + c.Params
+	}
+	if _, err := mysqldriver.ParseDSN(dsn); err != nil {
+		return "", fmt.Errorf("DB_PARAMS: %w", err)
+	}
+	return dsn, nil
+}
+
+// Redact заменяет пароль в строке подключения на "***".
+func (mysqlDialect) Redact(dsn string) string {
+	cfg, err := mysqldriver.ParseDSN(dsn)
+	if err != nil {
+		return "<invalid dsn>"
+	}
+	if cfg.Passwd != "" {
+		cfg.Passwd = "***"
+	}
+	return cfg.FormatDSN()
+}
+
+// Open возвращает диалектор GORM для MySQL.
+func (mysqlDialect) Open(dsn string) gorm.Dialector {
+	return mysql.New(mysql.Config{
+		DSN:                       dsn,
+		DefaultStringSize:         256,
+		DisableDatetimePrecision:  true,
+		DontSupportRenameIndex:    true,
+		DontSupportRenameColumn:   true,
+		SkipInitializeWithVersion: false,
+	})
+}
+
+// RandomOrder возвращает выражение случайного порядка MySQL.
+func (mysqlDialect) RandomOrder() string {
+	return "RAND()"
+}
+
+// Search ищет через MATCH ... AGAINST; требуется индекс FULLTEXT, см. EnsureSearchIndex.
+func (mysqlDialect) Search(db *gorm.DB, query string, columns ...string) *gorm.DB {
+	return db.Where("MATCH("+strings.Join(columns, ", ")+") AGAINST (? IN NATURAL LANGUAGE MODE)", query)
+}
+
+// EnsureSearchIndex создаёт индекс FULLTEXT по столбцам таблицы.
+func (mysqlDialect) EnsureSearchIndex(db *gorm.DB, table string, columns ...string) error {
+	name := searchIndexName(table)
+	if db.Migrator().HasIndex(table, name) {
+		return nil
+	}
+	return db.Exec("CREATE FULLTEXT INDEX " + name + " ON " + table + " (" + strings.Join(columns, ", ") + ")").Error
+}
+
+// DropSearchIndex удаляет индекс FULLTEXT таблицы.
+func (mysqlDialect) DropSearchIndex(db *gorm.DB, table string) error {
+	name := searchIndexName(table)
+	if !db.Migrator().HasIndex(table, name) {
+		return nil
+	}
+	return db.Migrator().DropIndex(table, name)
+}
+
+// Lock захватывает блокировку через GET_LOCK.
+func (mysqlDialect) Lock(conn *gorm.DB, name string, timeout time.Duration) error {
+	var acquired *int
+	if err := conn.Raw("SELECT GET_LOCK(?, ?)", name, int(timeout.Seconds())).Scan(&acquired).Error; err != nil {
+		return err
+	}
+	if acquired == nil || *acquired != 1 {
+		return ErrLockTimeout
+	}
+	return nil
+}
+
+// Unlock освобождает блокировку через RELEASE_LOCK.
+func (mysqlDialect) Unlock(conn *gorm.DB, name string) error {
+	return conn.Exec("SELECT RELEASE_LOCK(?)", name).Error
+}
@@ -0,0 +1,124 @@
+// Package database отвечает за инициализацию и настройку подключения к базе данных.
+package database
+
+import (
+	"hash/fnv"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// postgresPassword находит пароль в строке подключения PostgreSQL формата "ключ=значение".
+var postgresPassword = regexp.MustCompile(`(password=)('(?:[^'\\]|\\.)*'|\S+)`)
+
+// postgresDialect — диалект PostgreSQL.
+type postgresDialect struct{}
+
+// Name возвращает имя драйвера.
+func (postgresDialect) Name() string {
+	return "postgres"
+}
+
+// DSN собирает строку подключения PostgreSQL в виде URL.
+func (postgresDialect) DSN(c Config) (string, error) {
+	if c.DSN != "" {
+		return c.DSN, nil
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     net.JoinHostPort(c.Host, c.Port),
+		Path:     "/" + c.Name,
+		RawQuery: c.Params,
+	}
+	if c.Password == "" {
+		u.User = url.User(c.User)
+	}
+	return u.String(), nil
+}
+
+// Redact заменяет пароль в строке подключения на "***".
+func (postgresDialect) Redact(dsn string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "<invalid dsn>"
+		}
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), "***")
+		}
+		return u.String()
+	}
+	return postgresPassword.ReplaceAllString(dsn, "${1}***")
+}
+
+// Open возвращает диалектор GORM для PostgreSQL.
+func (postgresDialect) Open(dsn string) gorm.Dialector {
+	return postgres.Open(dsn)
+}
+
+// RandomOrder возвращает выражение случайного порядка PostgreSQL.
+func (postgresDialect) RandomOrder() string {
+	return "RANDOM()"
+}
+
+// Search ищет по выражению to_tsvector, совпадающему с индексом из EnsureSearchIndex.
+func (postgresDialect) Search(db *gorm.DB, query string, columns ...string) *gorm.DB {
+	return db.Where(postgresDocument(columns)+" @@ plainto_tsquery('simple', ?)", query)
+}
+
+// EnsureSearchIndex создаёт индекс GIN по выражению to_tsvector.
+func (postgresDialect) EnsureSearchIndex(db *gorm.DB, table string, columns ...string) error {
+	return db.Exec("CREATE INDEX IF NOT EXISTS " + searchIndexName(table) + " ON " + table +
+		" USING GIN (" + postgresDocument(columns) + ")").Error
+}
+
+// postgresDocument возвращает выражение to_tsvector по столбцам columns.
+func postgresDocument(columns []string) string {
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		parts[i] = "coalesce(" + column + ", '')"
+	}
+	return "to_tsvector('simple', " + strings.Join(parts, " || ' ' || ") + ")"
+}
+
+// DropSearchIndex удаляет индекс GIN таблицы.
+func (postgresDialect) DropSearchIndex(db *gorm.DB, table string) error {
+	return db.Exec("DROP INDEX IF EXISTS " + searchIndexName(table)).Error
+}
+
+// Lock захватывает рекомендательную блокировку pg_try_advisory_lock, повторяя попытки до timeout.
+func (postgresDialect) Lock(conn *gorm.DB, name string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		var acquired bool
+		if err := conn.Raw("SELECT pg_try_advisory_lock(?)", advisoryKey(name)).Scan(&acquired).Error; err != nil {
+			return err
+		}
+		if acquired {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrLockTimeout
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// Unlock освобождает рекомендательную блокировку.
+func (postgresDialect) Unlock(conn *gorm.DB, name string) error {
+	return conn.Exec("SELECT pg_advisory_unlock(?)", advisoryKey(name)).Error
+}
+
+// advisoryKey преобразует имя блокировки в числовой ключ pg_advisory_lock.
+func advisoryKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,141 @@
+// Package database отвечает за инициализацию и настройку подключения к базе данных.
+package database
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// replicaKey — ключ контекста, разрешающий чтение с реплик.
+type replicaKey struct{}
+
+// replica — подключение к реплике для чтения и её состояние.
+type replica struct {
+	name    string                   // строка подключения без пароля для логов
+	connect func() (*gorm.DB, error) // открывает подключение к реплике
+	db      atomic.Pointer[gorm.DB]  // подключение, или nil, пока реплика ни разу не была доступна
+	healthy atomic.Bool              // результат последней проверки
+}
+
+// check проверяет доступность реплики, при необходимости подключаясь к ней,
+// и запоминает результат.
+func (r *replica) check(ctx context.Context) error {
+	db := r.db.Load()
+	if db == nil {
+		var err error
+		if db, err = r.connect(); err != nil {
+			r.healthy.Store(false)
+			return err
+		}
+		r.db.Store(db)
+	}
+	err := ping(ctx, db)
+	r.healthy.Store(err == nil)
+	return err
+}
+
+// replicaSet — реплики, между которыми по очереди распределяются чтения.
+type replicaSet struct {
+	mu    sync.RWMutex
+	pool  []*replica
+	next  atomic.Uint64
+	watch sync.Once
+}
+
+// replicas — реплики основного подключения DB.
+var replicas replicaSet
+
+// set заменяет набор реплик.
+func (s *replicaSet) set(pool []*replica) {
+	s.mu.Lock()
+	s.pool = pool
+	s.mu.Unlock()
+}
+
+// pick возвращает следующую исправную реплику или nil, если таких нет.
+func (s *replicaSet) pick() *gorm.DB {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n := uint64(len(s.pool))
+	start := s.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if r := s.pool[(start+i)%n]; r.healthy.Load() {
+			return r.db.Load()
+		}
+	}
+	return nil
+}
+
+// ReadFromReplica возвращает копию ctx, в которой Conn направляет запросы на реплики.
+// Используется для запросов, которые только читают данные. Внутри транзакции
+// запросы всегда выполняются на основной базе.
+func ReadFromReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicaKey{}, true)
+}
+
+// UsePrimary возвращает копию ctx, в которой Conn направляет запросы на основную базу,
+// даже если ctx был помечен ReadFromReplica. Нужен для записи и чтения сразу после неё.
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicaKey{}, false)
+}
+
+// readsFromReplica сообщает, разрешено ли в ctx чтение с реплик.
+func readsFromReplica(ctx context.Context) bool {
+	ok, _ := ctx.Value(replicaKey{}).(bool)
+	return ok
+}
+
+// HasReplicas сообщает, настроены ли реплики для чтения.
+func HasReplicas() bool {
+	replicas.mu.RLock()
+	defer replicas.mu.RUnlock()
+	return len(replicas.pool) > 0
+}
+
+// CheckReplicas проверяет доступность реплик. Недоступные реплики исключаются из чтения,
+// пока очередная проверка не пройдёт успешно; если исправных реплик нет,
+// чтения выполняются на основной базе. Возвращает число исправных реплик.
+func CheckReplicas(ctx context.Context) int {
+	replicas.mu.RLock()
+	pool := replicas.pool
+	replicas.mu.RUnlock()
+
+	healthy := 0
+	for _, r := range pool {
+		wasHealthy := r.healthy.Load()
+		err := r.check(ctx)
+		if ok := err == nil; ok != wasHealthy {
+			if ok {
+				log.Printf("database: replica %s is back online", r.name)
+			} else {
+				log.Printf("database: replica %s is unavailable, excluded from reads: %v", r.name, err)
+			}
+		}
+		if err == nil {
+			healthy++
+		}
+	}
+	return healthy
+}
+
+// WatchReplicas запускает фоновую проверку реплик (CheckReplicas) с периодом interval.
+// Повторные вызовы ничего не делают.
+func WatchReplicas(interval time.Duration) {
+	if !HasReplicas() || interval <= 0 {
+		return
+	}
+	replicas.watch.Do(func() {
+		CheckReplicas(context.Background())
+		go func() {
+			for range time.Tick(interval) {
+				CheckReplicas(context.Background())
+			}
+		}()
+	})
+}
@@ -0,0 +1,193 @@
+// Package database отвечает за инициализацию и настройку подключения к базе данных.
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// sqliteDialect — диалект SQLite.
+type sqliteDialect struct{}
+
+// Name возвращает имя драйвера.
+func (sqliteDialect) Name() string {
+	return "sqlite"
+}
+
+// DSN возвращает путь к файлу базы данных с параметрами подключения.
+func (sqliteDialect) DSN(c Config) (string, error) {
+	if c.DSN != "" {
+		return c.DSN, nil
+	}
+	if c.Params == "" {
+		return c.Name, nil
+	}
+	return c.Name + "?" + c.Params, nil
+}
+
+// Redact возвращает строку подключения без изменений: пароля в ней нет.
+func (sqliteDialect) Redact(dsn string) string {
+	return dsn
+}
+
+// Open возвращает диалектор GORM для SQLite.
+func (sqliteDialect) Open(dsn string) gorm.Dialector {
+	return sqlite.Open(dsn)
+}
+
+// RandomOrder возвращает выражение случайного порядка SQLite.
+func (sqliteDialect) RandomOrder() string {
+	return "RANDOM()"
+}
+
+// Search ищет подстроку без учёта регистра: полнотекстовый поиск SQLite (FTS5)
+// требует отдельных виртуальных таблиц, а для встроенной базы достаточно LIKE.
+func (sqliteDialect) Search(db *gorm.DB, query string, columns ...string) *gorm.DB {
+	pattern := "%" + escapeLike(query) + "%"
+	conditions := make([]string, len(columns))
+	args := make([]any, len(columns))
+	for i, column := range columns {
+		conditions[i] = column + ` LIKE ? ESCAPE '\'`
+		args[i] = pattern
+	}
+	return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+// EnsureSearchIndex ничего не делает: поиск через LIKE не использует индексы.
+func (sqliteDialect) EnsureSearchIndex(db *gorm.DB, table string, columns ...string) error {
+	return nil
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// DropSearchIndex ничего не делает: индекс не создаётся.
+func (sqliteDialect) DropSearchIndex(db *gorm.DB, table string) error {
+	return nil
+}
+
+// sqliteLockStaleAfter — время, после которого блокировка в schema_locks, захваченная
+// на другом хосте или без указания владельца, считается оставленной аварийно
+// завершившимся процессом и может быть захвачена другим.
+const sqliteLockStaleAfter = 15 * time.Minute
+
+// Lock захватывает блокировку вставкой строки в таблицу schema_locks: у SQLite нет
+// именованных блокировок. В строке сохраняются владелец (хост и PID) и время захвата.
+// Блокировка процесса на этом же хосте освобождается, только когда процесс завершился,
+// сколько бы ни длилась миграция; прочие — через sqliteLockStaleAfter (см. lockAbandoned).
+// Так после аварийного завершения процесса блокировку не нужно удалять вручную.
+func (sqliteDialect) Lock(conn *gorm.DB, name string, timeout time.Duration) error {
+	if err := conn.Exec("CREATE TABLE IF NOT EXISTS schema_locks (name VARCHAR(100) PRIMARY KEY, locked_at DATETIME, owner VARCHAR(255))").Error; err != nil {
+		return err
+	}
+	if !conn.Migrator().HasColumn("schema_locks", "owner") {
+		if err := conn.Exec("ALTER TABLE schema_locks ADD COLUMN owner VARCHAR(255)").Error; err != nil {
+			return err
+		}
+	}
+
+	owner := lockOwner()
+	deadline := time.Now().Add(timeout)
+	for {
+		var holders []lockHolder
+		if err := conn.Raw("SELECT owner, locked_at FROM schema_locks WHERE name = ?", name).Scan(&holders).Error; err != nil {
+			return err
+		}
+		if len(holders) == 1 && lockAbandoned(holders[0]) {
+			// Условие на владельца не даёт удалить блокировку, которую успел захватить другой процесс
+			stale := conn.Exec("DELETE FROM schema_locks WHERE name = ? AND COALESCE(owner, '') = ?", name, holders[0].Owner.String)
+			if stale.Error != nil {
+				return stale.Error
+			}
+			if stale.RowsAffected == 1 {
+				log.Printf("database: lock %q of %s is abandoned, taking it over", name, holders[0])
+			}
+		}
+
+		result := conn.Exec("INSERT OR IGNORE INTO schema_locks (name, locked_at, owner) VALUES (?, ?, ?)", name, time.Now().UTC(), owner)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			holder := "another process"
+			if len(holders) == 1 {
+				holder = holders[0].String()
+			}
+			return fmt.Errorf("%w: %q is held by %s", ErrLockTimeout, name, holder)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// Unlock освобождает блокировку, удаляя строку из schema_locks, если она
+// по-прежнему принадлежит этому процессу.
+func (sqliteDialect) Unlock(conn *gorm.DB, name string) error {
+	return conn.Exec("DELETE FROM schema_locks WHERE name = ? AND owner = ?", name, lockOwner()).Error
+}
+
+// lockHolder — владелец блокировки из schema_locks.
+type lockHolder struct {
+	Owner    sql.NullString // Хост и PID; NULL у блокировок, захваченных до появления столбца
+	LockedAt time.Time      // Время захвата
+}
+
+// String описывает владельца блокировки для логов и ошибок.
+func (h lockHolder) String() string {
+	owner := h.Owner.String
+	if owner == "" {
+		owner = "unknown owner"
+	}
+	return owner + " since " + h.LockedAt.Format(time.RFC3339)
+}
+
+// lockAbandoned сообщает, оставлена ли блокировка владельцем h. Процесс на этом хосте
+// держит блокировку, пока жив; владелец на другом хосте или неизвестный владелец —
+// не дольше sqliteLockStaleAfter, поскольку проверить его процесс нельзя.
+func lockAbandoned(h lockHolder) bool {
+	host, pid, ok := strings.Cut(h.Owner.String, ":")
+	if ok && host == hostname() {
+		if id, err := strconv.Atoi(pid); err == nil {
+			return !processAlive(id)
+		}
+	}
+	return time.Since(h.LockedAt) > sqliteLockStaleAfter
+}
+
+// processAlive проверяет, существует ли процесс pid, сигналом 0.
+// Если наличие процесса проверить не удалось, он считается живым.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return !errors.Is(p.Signal(syscall.Signal(0)), os.ErrProcessDone)
+}
+
+// lockOwner возвращает идентификатор владельца блокировки: имя хоста и PID процесса.
+func lockOwner() string {
+	return fmt.Sprintf("%s:%d", hostname(), os.Getpid())
+}
+
+// hostname возвращает имя хоста или "unknown", если его не удалось определить.
+func hostname() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return host
+}
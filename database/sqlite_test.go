@@ -0,0 +1,114 @@
+package database_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"server/database"
+	"server/database/dbtest"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// holdLock записывает в schema_locks блокировку name владельца owner, захваченную age назад.
+func holdLock(t *testing.T, db *gorm.DB, name, owner string, age time.Duration) {
+	t.Helper()
+	// Таблицу schema_locks создаёт сам Lock
+	if err := database.Current().Lock(db, "init", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Current().Unlock(db, "init"); err != nil {
+		t.Fatal(err)
+	}
+	err := db.Exec("INSERT INTO schema_locks (name, locked_at, owner) VALUES (?, ?, ?)", name, time.Now().UTC().Add(-age), owner).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// hostOwner возвращает идентификатор владельца блокировки процесса pid на этом хосте.
+func hostOwner(t *testing.T, pid int) string {
+	t.Helper()
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skip("hostname is unavailable")
+	}
+	return fmt.Sprintf("%s:%d", host, pid)
+}
+
+// deadPID возвращает PID завершившегося процесса.
+func deadPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return cmd.Process.Pid
+}
+
+func TestSQLiteLock(t *testing.T) {
+	db := dbtest.Open(t)
+	dialect := database.Current()
+
+	if err := dialect.Lock(db, "migrate", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := dialect.Lock(db, "migrate", 100*time.Millisecond); !errors.Is(err, database.ErrLockTimeout) {
+		t.Fatalf("expected ErrLockTimeout for a held lock, got %v", err)
+	}
+	if err := dialect.Lock(db, "other", time.Second); err != nil {
+		t.Fatalf("locks with different names must be independent: %v", err)
+	}
+	if err := dialect.Unlock(db, "migrate"); err != nil {
+		t.Fatal(err)
+	}
+	if err := dialect.Lock(db, "migrate", time.Second); err != nil {
+		t.Fatalf("expected released lock to be free: %v", err)
+	}
+}
+
+func TestSQLiteLockTakeover(t *testing.T) {
+	tests := []struct {
+		name     string
+		owner    func(t *testing.T) string
+		age      time.Duration
+		takeover bool
+	}{
+		{"live process on this host", func(t *testing.T) string { return hostOwner(t, os.Getppid()) }, time.Hour, false},
+		{"dead process on this host", func(t *testing.T) string { return hostOwner(t, deadPID(t)) }, 0, true},
+		{"recent lock on another host", func(*testing.T) string { return "elsewhere:1" }, time.Minute, false},
+		{"stale lock on another host", func(*testing.T) string { return "elsewhere:1" }, time.Hour, true},
+		{"stale lock without owner", func(*testing.T) string { return "" }, time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dbtest.Open(t)
+			holdLock(t, db, "migrate", tt.owner(t), tt.age)
+
+			err := database.Current().Lock(db, "migrate", 100*time.Millisecond)
+			if tt.takeover && err != nil {
+				t.Fatalf("expected the lock to be taken over, got %v", err)
+			}
+			if !tt.takeover && !errors.Is(err, database.ErrLockTimeout) {
+				t.Fatalf("expected ErrLockTimeout, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSQLiteUnlockKeepsForeignLock(t *testing.T) {
+	db := dbtest.Open(t)
+	holdLock(t, db, "migrate", hostOwner(t, os.Getppid()), 0)
+
+	if err := database.Current().Unlock(db, "migrate"); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	db.Raw("SELECT COUNT(*) FROM schema_locks WHERE name = ?", "migrate").Scan(&count)
+	if count != 1 {
+		t.Fatal("Unlock must not release a lock held by another process")
+	}
+}
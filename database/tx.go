@@ -0,0 +1,33 @@
+// Package database отвечает за инициализацию и настройку подключения к базе данных.
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey — ключ контекста, под которым хранится текущая транзакция.
+type txKey struct{}
+
+// WithTx возвращает копию ctx, в которой сохранена транзакция tx.
+// Conn(ctx) для такого контекста возвращает tx.
+func WithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext возвращает транзакцию, сохранённую в ctx, если она есть.
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok
+}
+
+// Transaction выполняет fn как единицу работы: открывает транзакцию, передаёт fn контекст
+// с ней и фиксирует её, если fn вернула nil. При ошибке или панике в fn транзакция
+// откатывается, паника передаётся дальше. Если ctx уже содержит транзакцию, fn выполняется
+// во вложенной транзакции на точке сохранения (SAVEPOINT), и откат затрагивает только её.
+func Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return Conn(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(WithTx(ctx, tx))
+	})
+}
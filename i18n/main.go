@@ -0,0 +1,109 @@
+// Package i18n реализует согласование языка клиента и перевод сообщений API.
+// Каталоги сообщений хранятся в файлах locales/<язык>.json и встраиваются в бинарный файл.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale — язык, используемый при отсутствии подходящего варианта.
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalogs содержит сообщения по языкам: язык → ключ → шаблон сообщения.
+var catalogs = loadCatalogs()
+
+// Localizable реализуется ошибками, которые умеют переводить своё описание.
+type Localizable interface {
+	Localize(t func(key string, args ...any) string) string
+}
+
+// Translate возвращает сообщение key на языке locale, подставляя args через fmt.Sprintf.
+// Если сообщения нет в каталоге языка, используется DefaultLocale, а затем сам ключ.
+func Translate(locale, key string, args ...any) string {
+	format, ok := catalogs[locale][key]
+	if !ok {
+		if format, ok = catalogs[DefaultLocale][key]; !ok {
+			format = key
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Supported проверяет, есть ли каталог сообщений для языка locale.
+func Supported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Negotiate выбирает поддерживаемый язык по значению заголовка Accept-Language
+// с учётом весов q. Региональные варианты сводятся к основному языку ("ru-RU" → "ru").
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if val, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{tag: strings.ToLower(tag), q: q})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	for _, c := range candidates {
+		primary, _, _ := strings.Cut(c.tag, "-")
+		if Supported(primary) {
+			return primary
+		}
+	}
+	return DefaultLocale
+}
+
+// loadCatalogs загружает встроенные каталоги сообщений.
+func loadCatalogs() map[string]map[string]string {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+
+	result := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := localeFiles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %v", entry.Name(), err))
+		}
+		result[strings.TrimSuffix(entry.Name(), ".json")] = messages
+	}
+	return result
+}
@@ -0,0 +1,85 @@
+// Package ipfilter ограничивает доступ к маршрутам по IP-адресу клиента.
+package ipfilter
+
+import (
+	"net"
+	"net/http"
+	"server/request"
+	"server/router"
+	"server/types"
+	"sync/atomic"
+)
+
+// Filter проверяет IP-адреса клиентов по правилам, которые можно заменить на лету.
+type Filter struct {
+	rules atomic.Pointer[compiled]
+}
+
+// New создаёт фильтр с правилами rules.
+func New(rules Rules) (*Filter, error) {
+	f := &Filter{}
+	if err := f.Update(rules); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Update атомарно заменяет правила фильтра. При ошибке разбора действуют прежние правила.
+func (f *Filter) Update(rules Rules) error {
+	c, err := compile(rules)
+	if err != nil {
+		return err
+	}
+	f.rules.Store(c)
+	return nil
+}
+
+// Allowed проверяет, разрешён ли доступ с адреса ip.
+func (f *Filter) Allowed(ip net.IP) bool {
+	rules := f.rules.Load()
+	if ip == nil {
+		return len(rules.allow) == 0 && len(rules.deny) == 0
+	}
+	if contains(rules.deny, ip) {
+		return false
+	}
+	return len(rules.allow) == 0 || contains(rules.allow, ip)
+}
+
+// Middleware отклоняет с кодом 403 запросы с адресов, не прошедших фильтр.
+// Адрес клиента определяется через request.ClientIP с учётом доверенных прокси.
+func (f *Filter) Middleware() types.Middleware {
+	return func(next types.HandlerFunc) types.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			req := request.InitRequest(r)
+			if !f.Allowed(net.ParseIP(req.ClientIP())) {
+				router.WriteJson(w, r, types.JsonResponse{Status: "error", Message: req.T("ip.forbidden"), Code: http.StatusForbidden})
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// compile разбирает сети правил.
+func compile(rules Rules) (*compiled, error) {
+	allow, err := request.ParseCIDRs(rules.Allow)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := request.ParseCIDRs(rules.Deny)
+	if err != nil {
+		return nil, err
+	}
+	return &compiled{allow: allow, deny: deny}, nil
+}
+
+// contains проверяет, входит ли ip хотя бы в одну из сетей nets.
+func contains(nets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package ipfilter
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"server/request"
+	"testing"
+)
+
+func TestFilterAllowed(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		ip    string
+		want  bool
+	}{
+		{"no rules", Rules{}, "203.0.113.7", true},
+		{"allow network", Rules{Allow: []string{"10.0.0.0/8"}}, "10.1.2.3", true},
+		{"outside allowed network", Rules{Allow: []string{"10.0.0.0/8"}}, "11.0.0.1", false},
+		{"network boundary", Rules{Allow: []string{"192.168.1.0/25"}}, "192.168.1.127", true},
+		{"past network boundary", Rules{Allow: []string{"192.168.1.0/25"}}, "192.168.1.128", false},
+		{"single address", Rules{Allow: []string{"203.0.113.7"}}, "203.0.113.7", true},
+		{"other single address", Rules{Allow: []string{"203.0.113.7"}}, "203.0.113.8", false},
+		{"deny wins over allow", Rules{Allow: []string{"10.0.0.0/8"}, Deny: []string{"10.0.0.0/24"}}, "10.0.0.5", false},
+		{"deny only", Rules{Deny: []string{"198.51.100.0/24"}}, "203.0.113.7", true},
+		{"ipv6 network", Rules{Allow: []string{"2001:db8::/32"}}, "2001:db8::1", true},
+		{"ipv6 outside network", Rules{Allow: []string{"2001:db8::/32"}}, "2001:db9::1", false},
+		{"ipv4-mapped ipv6 address", Rules{Allow: []string{"10.0.0.0/8"}}, "::ffff:10.0.0.1", true},
+		{"ipv4 rule does not match ipv6", Rules{Allow: []string{"127.0.0.0/8"}}, "::1", false},
+		{"deny all ipv4", Rules{Deny: []string{"0.0.0.0/0", "::/0"}}, "127.0.0.1", false},
+		{"deny all ipv6", Rules{Deny: []string{"0.0.0.0/0", "::/0"}}, "::1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(tt.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := f.Allowed(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("Allowed(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterUnknownAddress(t *testing.T) {
+	open, _ := New(Rules{})
+	if !open.Allowed(nil) {
+		t.Error("filter without rules should allow an unknown address")
+	}
+	restricted, _ := New(Rules{Deny: []string{"198.51.100.0/24"}})
+	if restricted.Allowed(nil) {
+		t.Error("filter with rules should reject an unknown address")
+	}
+}
+
+func TestFilterInvalidRules(t *testing.T) {
+	for _, rules := range []Rules{{Allow: []string{"10.0.0.0/33"}}, {Deny: []string{"localhost"}}} {
+		if _, err := New(rules); err == nil {
+			t.Errorf("expected error for %+v", rules)
+		}
+	}
+
+	f, _ := New(Rules{Allow: []string{"10.0.0.0/8"}})
+	if err := f.Update(Rules{Allow: []string{"bad"}}); err == nil {
+		t.Fatal("expected error for invalid update")
+	}
+	if !f.Allowed(net.ParseIP("10.0.0.1")) || f.Allowed(net.ParseIP("11.0.0.1")) {
+		t.Error("failed update must keep the previous rules")
+	}
+}
+
+func TestFilterMiddleware(t *testing.T) {
+	if err := request.SetTrustedProxies([]string{"127.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { request.SetTrustedProxies(nil) })
+
+	f, _ := New(Rules{Allow: []string{"10.0.0.0/8"}})
+	handler := f.Middleware()(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name   string
+		remote string
+		xff    string
+		want   int
+	}{
+		{"allowed client", "10.0.0.1:1234", "", http.StatusNoContent},
+		{"rejected client", "203.0.113.7:1234", "", http.StatusForbidden},
+		{"allowed client behind proxy", "127.0.0.1:1234", "10.0.0.1", http.StatusNoContent},
+		{"rejected client behind proxy", "127.0.0.1:1234", "203.0.113.7", http.StatusForbidden},
+		{"untrusted sender cannot spoof", "203.0.113.7:1234", "10.0.0.1", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			w := httptest.NewRecorder()
+			handler(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
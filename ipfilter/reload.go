@@ -0,0 +1,106 @@
+// Package ipfilter ограничивает доступ к маршрутам по IP-адресу клиента.
+package ipfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Reloader загружает правила именованных фильтров из JSON-файла и обновляет их
+// при изменении файла или по сигналу SIGHUP.
+type Reloader struct {
+	path     string
+	filters  map[string]*Filter
+	defaults Config
+	modTime  time.Time
+}
+
+// NewReloader создаёт загрузчик правил из файла path для фильтров filters.
+// Фильтр, для которого в файле нет правил, получает правила по умолчанию
+// (см. WithDefaults), а без них пропускает все адреса.
+func NewReloader(path string, filters map[string]*Filter) *Reloader {
+	return &Reloader{path: path, filters: filters}
+}
+
+// WithDefaults задаёт правила фильтров, для которых в файле нет правил.
+func (r *Reloader) WithDefaults(defaults Config) *Reloader {
+	r.defaults = defaults
+	return r
+}
+
+// Reload перечитывает файл и обновляет все фильтры. Если правила хотя бы одного
+// фильтра некорректны, ни один фильтр не изменяется.
+func (r *Reloader) Reload() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+	// Время запоминается и при ошибке, чтобы не повторять её до следующего изменения файла
+	r.modTime = info.ModTime()
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return err
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("%s: %w", r.path, err)
+	}
+	for name := range config {
+		if _, ok := r.filters[name]; !ok {
+			return fmt.Errorf("%s: unknown filter %q", r.path, name)
+		}
+	}
+
+	rules := make(map[string]*compiled, len(r.filters))
+	for name := range r.filters {
+		filterRules, ok := config[name]
+		if !ok {
+			if filterRules, ok = r.defaults[name]; ok {
+				log.Printf("ipfilter: %s: no rules for filter %q, using defaults %v", r.path, name, filterRules)
+			}
+		}
+		c, err := compile(filterRules)
+		if err != nil {
+			return fmt.Errorf("%s: filter %q: %w", r.path, name, err)
+		}
+		rules[name] = c
+	}
+	for name, filter := range r.filters {
+		filter.rules.Store(rules[name])
+	}
+	return nil
+}
+
+// Watch в фоне перечитывает файл по сигналу SIGHUP и при изменении времени
+// его модификации, проверяя его каждые interval. Ошибки записываются в лог,
+// при этом продолжают действовать прежние правила.
+func (r *Reloader) Watch(interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		for {
+			select {
+			case <-hup:
+			case <-ticker.C:
+				info, err := os.Stat(r.path)
+				if err != nil || info.ModTime().Equal(r.modTime) {
+					continue
+				}
+			}
+			if err := r.Reload(); err != nil {
+				log.Printf("ipfilter: reload failed: %v", err)
+				continue
+			}
+			log.Printf("ipfilter: rules reloaded from %s", r.path)
+		}
+	}()
+}
@@ -0,0 +1,90 @@
+package ipfilter
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeRules записывает файл правил path с содержимым content.
+func writeRules(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// expectAllowed проверяет решение фильтра f для адреса ip.
+func expectAllowed(t *testing.T, f *Filter, ip string, want bool) {
+	t.Helper()
+	if got := f.Allowed(net.ParseIP(ip)); got != want {
+		t.Errorf("Allowed(%s) = %v, want %v", ip, got, want)
+	}
+}
+
+// newTestReloader создаёт фильтры global и admin и загрузчик правил из временного файла.
+func newTestReloader(t *testing.T) (reloader *Reloader, path string, global, admin *Filter) {
+	t.Helper()
+	path = filepath.Join(t.TempDir(), "ipfilter.json")
+	global, _ = New(Rules{})
+	admin, _ = New(Rules{})
+	reloader = NewReloader(path, map[string]*Filter{"global": global, "admin": admin}).
+		WithDefaults(Config{"admin": {Allow: []string{"127.0.0.1"}}})
+	return reloader, path, global, admin
+}
+
+func TestReloaderReload(t *testing.T) {
+	reloader, path, global, admin := newTestReloader(t)
+
+	writeRules(t, path, `{"global": {"deny": ["198.51.100.0/24"]}, "admin": {"allow": ["10.0.0.0/8"]}}`)
+	if err := reloader.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	expectAllowed(t, global, "198.51.100.1", false)
+	expectAllowed(t, global, "203.0.113.7", true)
+	expectAllowed(t, admin, "10.0.0.1", true)
+	expectAllowed(t, admin, "127.0.0.1", false)
+
+	// Фильтр без правил в файле получает правила по умолчанию, а без них пропускает всех
+	writeRules(t, path, `{}`)
+	if err := reloader.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	expectAllowed(t, global, "198.51.100.1", true)
+	expectAllowed(t, admin, "127.0.0.1", true)
+	expectAllowed(t, admin, "10.0.0.1", false)
+}
+
+func TestReloaderInvalidFile(t *testing.T) {
+	reloader, path, global, admin := newTestReloader(t)
+	writeRules(t, path, `{"global": {"deny": ["198.51.100.0/24"]}, "admin": {"allow": ["10.0.0.0/8"]}}`)
+	if err := reloader.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ни одна ошибка не должна менять действующие правила, в том числе правила корректных фильтров
+	for name, content := range map[string]string{
+		"invalid json":   `{"global": `,
+		"unknown filter": `{"global": {}, "backup": {"allow": ["10.0.0.0/8"]}}`,
+		"invalid rule":   `{"global": {}, "admin": {"allow": ["10.0.0.0/40"]}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			writeRules(t, path, content)
+			if err := reloader.Reload(); err == nil {
+				t.Fatal("expected error")
+			}
+			expectAllowed(t, global, "198.51.100.1", false)
+			expectAllowed(t, admin, "10.0.0.1", true)
+			expectAllowed(t, admin, "127.0.0.1", false)
+		})
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloader.Reload(); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+	expectAllowed(t, global, "198.51.100.1", false)
+}
@@ -0,0 +1,20 @@
+// Package ipfilter ограничивает доступ к маршрутам по IP-адресу клиента.
+package ipfilter
+
+import "net"
+
+// Rules задаёт правила доступа в виде CIDR или отдельных IP-адресов.
+// Адрес из Deny отклоняется всегда; если Allow не пуст, допускаются только адреса из него.
+type Rules struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// Config — правила именованных фильтров, например {"global": {...}, "admin": {...}}.
+type Config map[string]Rules
+
+// compiled — разобранные правила.
+type compiled struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
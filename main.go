@@ -1,25 +1,85 @@
 // Package main содержит точку входа в приложение сервера для работы с пивом и закусками.
-// В функции main происходит инициализация базы данных, автоматическая миграция моделей и запуск сервера.
+// В функции main происходит загрузка конфигурации (файл из переменной CONFIG_FILE),
+// подключение к базе данных с повторными попытками, проверка миграций схемы и запуск сервера.
+// Если переданы аргументы командной строки, вместо сервера выполняется консольная команда,
+// например "apikey issue <name>".
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"os"
+	"server/commands"
+	"server/config"
 	"server/database"
-	"server/models"
+	"server/migrate"
+	"server/migrations"
+	"server/rbac"
 	"server/router"
 	"server/server"
 )
 
 var apiRouter router.Router
 
-// main инициализирует соединение с базой данных, выполняет миграцию моделей Beer и Snack,
-// а затем запускает HTTP-сервер.
+// cliConnectAttempts — число попыток подключения к базе данных для консольных команд по умолчанию.
+const cliConnectAttempts = 3
+
+// main загружает конфигурацию и запускает консольную команду или HTTP-сервер.
+// Сервер повторяет подключение к базе данных, пока СУБД не станет доступна (см. database.Connect),
+// и при этом уже принимает запросы, но не готов их обслуживать. Консольные команды
+// по умолчанию делают не больше cliConnectAttempts попыток, чтобы быстро сообщать об ошибке настройки.
+// Сервер не становится готовым, пока в базе есть неприменённые миграции.
 func main() {
-	if err := database.Init(); err != nil {
-		log.Fatalf("failed to init db: %v", err)
+	if err := config.Load(os.Getenv("CONFIG_FILE")); err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	dbConfig, err := database.LoadConfig()
+	if err != nil {
+		log.Fatalf("invalid database config: %v", err)
+	}
+
+	if len(os.Args) > 1 {
+		dbConfig.ConnectAttempts = config.Int("DB_CONNECT_ATTEMPTS", cliConnectAttempts)
+		if _, err := prepareDatabase(dbConfig); err != nil {
+			log.Fatal(err)
+		}
+		if err := commands.Run(os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
-	database.DB.AutoMigrate(&models.Beer{})
-	database.DB.AutoMigrate(&models.Snack{})
 
-	server.Init()
+	server.Init(func() error {
+		migrator, err := prepareDatabase(dbConfig)
+		if err != nil {
+			return err
+		}
+		pending, err := migrator.Pending()
+		if err != nil {
+			return fmt.Errorf("failed to check migrations: %w", err)
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("database schema is out of date: %d pending migrations, run \"migrate up\" or set MIGRATE_ON_START=true", len(pending))
+		}
+		if err := rbac.EnsureDefaultRoles(database.DB); err != nil {
+			return fmt.Errorf("failed to create default roles: %w", err)
+		}
+		return nil
+	})
+}
+
+// prepareDatabase подключается к базе данных с повторными попытками и применяет
+// миграции, если задано MIGRATE_ON_START.
+func prepareDatabase(cfg database.Config) (*migrate.Migrator, error) {
+	if err := database.Connect(context.Background(), cfg); err != nil {
+		return nil, fmt.Errorf("failed to init db: %w", err)
+	}
+	migrator := migrate.New(database.DB, migrations.All())
+	if config.Bool("MIGRATE_ON_START", false) {
+		if _, err := migrator.Up(0); err != nil {
+			return nil, fmt.Errorf("failed to migrate db: %w", err)
+		}
+	}
+	return migrator, nil
 }
@@ -0,0 +1,136 @@
+// Package middleware содержит middleware общего назначения для HTTP-маршрутизатора.
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"server/router"
+	"server/types"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSPolicy задаёт правила CORS для группы источников (Origin).
+type CORSPolicy struct {
+	// AllowedOrigins — разрешённые источники: точные ("https://app.example.com"),
+	// с подстановкой поддомена ("https://*.example.com") или "*" для любого источника.
+	AllowedOrigins []string
+	// AllowedMethods — разрешённые методы. Если пусто, разрешены все методы,
+	// зарегистрированные в маршрутизаторе для запрошенного пути.
+	AllowedMethods []string
+	// AllowedHeaders — разрешённые заголовки запроса. Если пусто, разрешаются
+	// заголовки, запрошенные в Access-Control-Request-Headers.
+	AllowedHeaders []string
+	// ExposedHeaders — заголовки ответа, доступные скриптам клиента.
+	ExposedHeaders []string
+	// AllowCredentials разрешает передачу cookie и заголовка Authorization.
+	AllowCredentials bool
+	// MaxAge — время кеширования ответа на preflight-запрос браузером.
+	MaxAge time.Duration
+}
+
+// Validate проверяет политику. Любой источник "*" вместе с AllowCredentials
+// позволил бы любому сайту выполнять запросы от имени пользователя, поэтому такое сочетание запрещено.
+func (p CORSPolicy) Validate() error {
+	if p.AllowCredentials && slices.Contains(p.AllowedOrigins, "*") {
+		return errors.New(`credentials cannot be allowed for origin "*"`)
+	}
+	return nil
+}
+
+// CORS возвращает middleware, добавляющий заголовки CORS по первой политике,
+// источник которой совпал с заголовком Origin запроса. Preflight-запросы
+// (OPTIONS с Access-Control-Request-Method) обрабатываются без вызова обработчика.
+func CORS(policies ...CORSPolicy) types.Middleware {
+	return func(next types.HandlerFunc) types.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Add("Vary", "Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if preflight {
+				header.Add("Vary", "Access-Control-Request-Method")
+				header.Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			policy, ok := matchPolicy(policies, origin)
+			if !ok {
+				next(w, r)
+				return
+			}
+
+			// Учётные данные никогда не разрешаются для источника "*" (см. CORSPolicy.Validate)
+			if slices.Contains(policy.AllowedOrigins, "*") {
+				header.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				header.Set("Access-Control-Allow-Origin", origin)
+				if policy.AllowCredentials {
+					header.Set("Access-Control-Allow-Credentials", "true")
+				}
+			}
+
+			if !preflight {
+				if len(policy.ExposedHeaders) > 0 {
+					header.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+				}
+				next(w, r)
+				return
+			}
+
+			methods := policy.AllowedMethods
+			if len(methods) == 0 {
+				methods = router.AllowedMethods(r)
+			}
+			if !slices.Contains(methods, strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))) {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+
+			if len(policy.AllowedHeaders) > 0 {
+				header.Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+			} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+				header.Set("Access-Control-Allow-Headers", requested)
+			}
+			if policy.MaxAge > 0 {
+				header.Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+// matchPolicy возвращает первую политику, разрешающую источник origin.
+func matchPolicy(policies []CORSPolicy, origin string) (CORSPolicy, bool) {
+	for _, policy := range policies {
+		for _, allowed := range policy.AllowedOrigins {
+			if matchOrigin(allowed, origin) {
+				return policy, true
+			}
+		}
+	}
+	return CORSPolicy{}, false
+}
+
+// matchOrigin сравнивает источник с шаблоном, поддерживая одну подстановку "*".
+func matchOrigin(pattern, origin string) bool {
+	if pattern == "*" {
+		return true
+	}
+	prefix, suffix, wildcard := strings.Cut(pattern, "*")
+	if !wildcard {
+		return strings.EqualFold(pattern, origin)
+	}
+	origin = strings.ToLower(origin)
+	prefix, suffix = strings.ToLower(prefix), strings.ToLower(suffix)
+	return len(origin) > len(prefix)+len(suffix) &&
+		strings.HasPrefix(origin, prefix) &&
+		strings.HasSuffix(origin, suffix)
+}
@@ -0,0 +1,48 @@
+// Package middleware содержит middleware общего назначения для HTTP-маршрутизатора.
+package middleware
+
+import (
+	"net/http"
+	"server/database"
+	"server/types"
+	"time"
+)
+
+// PrimaryCookie — cookie, которая после записи направляет чтения клиента на основную базу.
+const PrimaryCookie = "db_primary"
+
+// ReadReplicas направляет запросы GET и HEAD на реплики для чтения (см. database.ReadFromReplica),
+// остальные — на основную базу. Чтобы клиент сразу видел свои изменения несмотря на задержку
+// репликации, после запроса на запись ему на время sticky выставляется cookie PrimaryCookie,
+// и пока она действует, его чтения тоже выполняются на основной базе.
+// Если реплики не настроены, middleware ничего не делает.
+func ReadReplicas(sticky time.Duration) types.Middleware {
+	return func(next types.HandlerFunc) types.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !database.HasReplicas() {
+				next(w, r)
+				return
+			}
+
+			switch r.Method {
+			case http.MethodGet, http.MethodHead:
+				if _, err := r.Cookie(PrimaryCookie); err != nil {
+					r = r.WithContext(database.ReadFromReplica(r.Context()))
+				}
+			case http.MethodOptions:
+			default:
+				if sticky > 0 {
+					http.SetCookie(w, &http.Cookie{
+						Name:     PrimaryCookie,
+						Value:    "1",
+						Path:     "/",
+						MaxAge:   int(sticky.Seconds()),
+						HttpOnly: true,
+						SameSite: http.SameSiteLaxMode,
+					})
+				}
+			}
+			next(w, r)
+		}
+	}
+}
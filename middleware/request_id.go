@@ -0,0 +1,59 @@
+// Package middleware содержит middleware общего назначения для HTTP-маршрутизатора.
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"server/request"
+	"server/types"
+)
+
+// RequestIDHeader — заголовок, в котором передаётся идентификатор запроса.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength — максимальная длина идентификатора, принимаемого от клиента.
+const maxRequestIDLength = 128
+
+// RequestID присваивает каждому запросу идентификатор: берёт его из заголовка X-Request-ID,
+// если он корректен, или генерирует новый. Идентификатор сохраняется в контексте запроса
+// и возвращается клиенту в заголовке ответа.
+func RequestID() types.Middleware {
+	return func(next types.HandlerFunc) types.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+
+			w.Header().Set(RequestIDHeader, id)
+			next(w, r.WithContext(request.WithID(r.Context(), id)))
+		}
+	}
+}
+
+// validRequestID проверяет, что идентификатор от клиента не пуст, не слишком длинный
+// и состоит только из безопасных для логов символов.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID генерирует случайный идентификатор запроса из 16 байт в шестнадцатеричном виде.
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,174 @@
+// Package middleware содержит middleware общего назначения для HTTP-маршрутизатора.
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"server/auth"
+	"server/request"
+	"server/router"
+	"server/session"
+	"server/types"
+	"strconv"
+	"time"
+)
+
+// securityKey — тип ключа настройки обработчика для собственной политики заголовков.
+type securityKey struct{}
+
+// SecurityKey — ключ, под которым в router.Handle.Set задаётся собственная политика
+// заголовков безопасности маршрута:
+//
+//	policy := middleware.DefaultSecurityPolicy()
+//	policy.ContentSecurityPolicy = "default-src 'self'"
+//	r.Get("/docs/", handler).Set(middleware.SecurityKey, policy)
+var SecurityKey = securityKey{}
+
+// SecurityPolicy задаёт заголовки безопасности ответов. Пустые поля отключают
+// соответствующий заголовок. Заголовки, уже установленные обработчиком, не перезаписываются.
+type SecurityPolicy struct {
+	// HSTSMaxAge — срок Strict-Transport-Security; заголовок отправляется только по HTTPS.
+	HSTSMaxAge time.Duration
+	// HSTSIncludeSubdomains распространяет HSTS на поддомены.
+	HSTSIncludeSubdomains bool
+	// ContentTypeOptions — значение X-Content-Type-Options.
+	ContentTypeOptions string
+	// FrameOptions — значение X-Frame-Options.
+	FrameOptions string
+	// ReferrerPolicy — значение Referrer-Policy.
+	ReferrerPolicy string
+	// ContentSecurityPolicy — значение Content-Security-Policy для ответов text/html.
+	ContentSecurityPolicy string
+	// NoStoreAuthenticated запрещает кеширование ответов аутентифицированным клиентам
+	// и клиентам с сессией (см. NoStore).
+	NoStoreAuthenticated bool
+}
+
+// DefaultSecurityPolicy возвращает безопасную политику по умолчанию.
+func DefaultSecurityPolicy() SecurityPolicy {
+	return SecurityPolicy{
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+		ContentTypeOptions:    "nosniff",
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "no-referrer",
+		ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'; base-uri 'none'; form-action 'self'",
+		NoStoreAuthenticated:  true,
+	}
+}
+
+// SecurityHeaders добавляет в ответы заголовки безопасности по политике policy
+// или по политике маршрута, заданной через SecurityKey.
+func SecurityHeaders(policy SecurityPolicy) types.Middleware {
+	return func(next types.HandlerFunc) types.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			p := routePolicy(r, policy)
+			sw := &securityWriter{ResponseWriter: w}
+			sw.before = func() {
+				p.apply(w.Header(), request.InitRequest(r))
+			}
+
+			next(sw, r)
+			sw.flush()
+		}
+	}
+}
+
+// apply устанавливает заголовки политики, которые ещё не заданы обработчиком.
+func (p SecurityPolicy) apply(header http.Header, r *request.Request) {
+	setDefault := func(key, value string) {
+		if value != "" && header.Get(key) == "" {
+			header.Set(key, value)
+		}
+	}
+
+	if p.HSTSMaxAge > 0 && r.Scheme() == "https" {
+		hsts := "max-age=" + strconv.Itoa(int(p.HSTSMaxAge.Seconds()))
+		if p.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		setDefault("Strict-Transport-Security", hsts)
+	}
+	setDefault("X-Content-Type-Options", p.ContentTypeOptions)
+	setDefault("X-Frame-Options", p.FrameOptions)
+	setDefault("Referrer-Policy", p.ReferrerPolicy)
+
+	if mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type")); mediaType == "text/html" {
+		setDefault("Content-Security-Policy", p.ContentSecurityPolicy)
+	}
+
+}
+
+// NoStore запрещает кеширование ответов, если этого требует NoStoreAuthenticated
+// политики policy или политики маршрута, заданной через SecurityKey. Решение принимается
+// перед отправкой ответа по клиенту из контекста запроса, а не по заголовкам: запрос
+// с непроверенными учётными данными анонимен, а клиент с сессией не передаёт заголовка
+// Authorization. Поэтому middleware подключается после аутентификации и сессий.
+func NoStore(policy SecurityPolicy) types.Middleware {
+	return func(next types.HandlerFunc) types.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !routePolicy(r, policy).NoStoreAuthenticated {
+				next(w, r)
+				return
+			}
+
+			sw := &securityWriter{ResponseWriter: w}
+			sw.before = func() {
+				if isPrivate(r) {
+					w.Header().Set("Cache-Control", "no-store")
+				}
+			}
+
+			next(sw, r)
+			sw.flush()
+		}
+	}
+}
+
+// isPrivate определяет, адресован ли ответ конкретному клиенту: клиент аутентифицирован
+// или у него есть сессия — пришедшая с запросом или созданная обработчиком.
+func isPrivate(r *http.Request) bool {
+	if auth.PrincipalFromContext(r.Context()) != nil {
+		return true
+	}
+	s := session.FromRequest(request.InitRequest(r))
+	return s != nil && (!s.IsNew() || len(s.Values) > 0)
+}
+
+// routePolicy возвращает политику маршрута запроса, заданную через SecurityKey, или policy.
+func routePolicy(r *http.Request, policy SecurityPolicy) SecurityPolicy {
+	if h := router.CurrentHandle(r); h != nil {
+		if override, ok := h.Value(SecurityKey).(SecurityPolicy); ok {
+			return override
+		}
+	}
+	return policy
+}
+
+// securityWriter вызывает функцию before непосредственно перед первой записью ответа,
+// когда обработчик уже установил Content-Type.
+type securityWriter struct {
+	http.ResponseWriter
+	before func()
+	done   bool
+}
+
+// flush вызывает before, если ответ так и не был записан.
+func (w *securityWriter) flush() {
+	if !w.done {
+		w.done = true
+		w.before()
+	}
+}
+
+// WriteHeader вызывает before и передаёт код ответа дальше.
+func (w *securityWriter) WriteHeader(code int) {
+	w.flush()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write вызывает before и передаёт тело ответа дальше.
+func (w *securityWriter) Write(b []byte) (int, error) {
+	w.flush()
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"server/auth"
+	"server/request"
+	"server/session"
+	"server/types"
+	"testing"
+)
+
+// withPrincipal сохраняет в контексте запроса пользователя, как это делает auth.Middleware.
+func withPrincipal(next types.HandlerFunc) types.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		next(w, r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{Kind: "user", ID: 1})))
+	}
+}
+
+// noStoreHandler создаёт цепочку: хранилище сессий, затем middlewares и NoStore с политикой policy.
+// Обработчик сохраняет значение в сессии, если в запросе есть параметр login.
+func noStoreHandler(t *testing.T, policy SecurityPolicy, middlewares ...types.Middleware) types.HandlerFunc {
+	t.Helper()
+	store, err := session.NewCookieStore(session.Options{}, []byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NoStore(policy)(func(w http.ResponseWriter, r *http.Request) {
+		req := request.InitRequest(r)
+		if req.Query("login", "") != "" {
+			session.FromRequest(req).Set("user", 1)
+		}
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		w.Write([]byte("{}"))
+	})
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return session.Middleware(store)(handler)
+}
+
+// cacheControl выполняет запрос и возвращает заголовок Cache-Control ответа.
+func cacheControl(handler types.HandlerFunc, req *http.Request) (string, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	handler(w, req)
+	return w.Header().Get("Cache-Control"), w
+}
+
+func TestNoStore(t *testing.T) {
+	policy := DefaultSecurityPolicy()
+
+	anonymous := noStoreHandler(t, policy)
+	if got, _ := cacheControl(anonymous, httptest.NewRequest(http.MethodGet, "/", nil)); got != "public, max-age=60" {
+		t.Errorf("anonymous response: Cache-Control = %q", got)
+	}
+
+	// Заголовок Authorization без аутентифицированного клиента не делает ответ личным
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer ignored")
+	if got, _ := cacheControl(anonymous, req); got != "public, max-age=60" {
+		t.Errorf("unauthenticated Authorization header: Cache-Control = %q", got)
+	}
+
+	authenticated := noStoreHandler(t, policy, withPrincipal)
+	if got, _ := cacheControl(authenticated, httptest.NewRequest(http.MethodGet, "/", nil)); got != "no-store" {
+		t.Errorf("authenticated response: Cache-Control = %q", got)
+	}
+
+	disabled := policy
+	disabled.NoStoreAuthenticated = false
+	if got, _ := cacheControl(noStoreHandler(t, disabled, withPrincipal), httptest.NewRequest(http.MethodGet, "/", nil)); got != "public, max-age=60" {
+		t.Errorf("disabled policy: Cache-Control = %q", got)
+	}
+}
+
+func TestNoStoreSession(t *testing.T) {
+	handler := noStoreHandler(t, DefaultSecurityPolicy())
+
+	// Ответ, создающий сессию, и следующий запрос с её cookie не кешируются
+	got, w := cacheControl(handler, httptest.NewRequest(http.MethodGet, "/?login=1", nil))
+	if got != "no-store" {
+		t.Errorf("response creating a session: Cache-Control = %q", got)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) == 0 {
+		t.Fatal("expected a session cookie")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+	if got, _ := cacheControl(handler, req); got != "no-store" {
+		t.Errorf("request with a session: Cache-Control = %q", got)
+	}
+}
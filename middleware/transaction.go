@@ -0,0 +1,85 @@
+// Package middleware содержит middleware общего назначения для HTTP-маршрутизатора.
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"server/database"
+	"server/router"
+	"server/types"
+)
+
+// errRollback откатывает транзакцию запроса, завершившегося ошибкой.
+var errRollback = errors.New("request failed, rolling back")
+
+// Transaction выполняет обработчик в транзакции базы данных, доступной ему через
+// database.Conn(r.Context()). Транзакция фиксируется, если обработчик ответил успешно,
+// и откатывается, если он вернул ответ со статусом "error" или кодом 4xx/5xx либо запаниковал.
+// Ответ буферизуется до фиксации, чтобы при её ошибке клиент получил 500, а не успешный ответ.
+// Подключается к маршрутизатору через Router.Transactions и включается для маршрута
+// через Handle.Transactional.
+func Transaction() types.Middleware {
+	return func(next types.HandlerFunc) types.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			tw := &txWriter{header: make(http.Header)}
+			err := database.Transaction(r.Context(), func(ctx context.Context) error {
+				next(tw, r.WithContext(ctx))
+				if tw.failed || tw.code >= http.StatusBadRequest {
+					return errRollback
+				}
+				return nil
+			})
+			if err != nil && !errors.Is(err, errRollback) {
+				log.Printf("transaction: %s %s: %v", r.Method, r.URL.Path, err)
+				router.WriteJson(w, r, types.JsonResponse{Status: "error", Message: err.Error(), Code: http.StatusInternalServerError})
+				return
+			}
+			tw.flush(w)
+		}
+	}
+}
+
+// txWriter буферизует ответ обработчика до завершения транзакции.
+type txWriter struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+	failed bool
+}
+
+// Header возвращает заголовки буферизованного ответа.
+func (w *txWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader запоминает код ответа.
+func (w *txWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+// Write дописывает тело ответа в буфер.
+func (w *txWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+// RecordError отмечает, что обработчик вернул ответ с ошибкой.
+func (w *txWriter) RecordError() {
+	w.failed = true
+}
+
+// flush отправляет буферизованный ответ в w.
+func (w *txWriter) flush(dst http.ResponseWriter) {
+	for key, values := range w.header {
+		dst.Header()[key] = values
+	}
+	if w.code != 0 {
+		dst.WriteHeader(w.code)
+	}
+	dst.Write(w.body.Bytes())
+}
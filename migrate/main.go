@@ -0,0 +1,213 @@
+// Package migrate применяет и откатывает версионированные миграции схемы базы данных.
+package migrate
+
+import (
+	"fmt"
+	"server/database"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lockName — имя блокировки, не позволяющей нескольким процессам мигрировать одновременно.
+const lockName = "schema_migrations"
+
+// Migrator применяет миграции к базе данных. Каждая миграция выполняется в отдельной
+// транзакции вместе с записью в schema_migrations; в MySQL DDL-операции не транзакционны,
+// поэтому при ошибке частично применённую миграцию нужно исправлять вручную.
+type Migrator struct {
+	db          *gorm.DB
+	migrations  []Migration
+	LockTimeout time.Duration // Время ожидания блокировки другого процесса
+}
+
+// New создаёт мигратор для списка миграций. Паникует при повторяющихся версиях.
+func New(db *gorm.DB, migrations []Migration) *Migrator {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			panic(fmt.Sprintf("migrate: duplicate migration version %d", sorted[i].Version))
+		}
+	}
+	return &Migrator{db: db, migrations: sorted, LockTimeout: time.Minute}
+}
+
+// Up применяет не более steps неприменённых миграций по возрастанию версий
+// (все, если steps <= 0) и возвращает применённые.
+func (m *Migrator) Up(steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(func(conn *gorm.DB) error {
+		applied, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if steps > 0 && len(done) == steps {
+				break
+			}
+			if err := up(conn, migration); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down откатывает не более steps последних применённых миграций (одну, если steps <= 0)
+// и возвращает откаченные.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+	var done []Migration
+	err := m.locked(func(conn *gorm.DB) error {
+		var err error
+		done, err = m.down(conn, steps)
+		return err
+	})
+	return done, err
+}
+
+// Redo откатывает последнюю применённую миграцию и применяет её снова.
+func (m *Migrator) Redo() (*Migration, error) {
+	var migration *Migration
+	err := m.locked(func(conn *gorm.DB) error {
+		done, err := m.down(conn, 1)
+		if err != nil || len(done) == 0 {
+			return err
+		}
+		migration = &done[0]
+		return up(conn, *migration)
+	})
+	return migration, err
+}
+
+// Status возвращает состояние всех миграций, включая применённые, но отсутствующие в коде.
+func (m *Migrator) Status() ([]Status, error) {
+	if err := m.db.AutoMigrate(&record{}); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if rec, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &rec.AppliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, rec := range applied {
+		statuses = append(statuses, Status{Version: rec.Version, Name: rec.Name, AppliedAt: &rec.AppliedAt, Missing: true})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Pending возвращает неприменённые миграции.
+func (m *Migrator) Pending() ([]Migration, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, m.find(status.Version))
+		}
+	}
+	return pending, nil
+}
+
+// locked выполняет fn на закреплённом соединении под блокировкой мигратора.
+func (m *Migrator) locked(fn func(conn *gorm.DB) error) error {
+	return m.db.Connection(func(conn *gorm.DB) error {
+		// Соединение разделяет состояние запроса между вызовами; NewDB даёт каждому вызову чистое
+		conn = conn.Session(&gorm.Session{NewDB: true})
+		dialect := database.Current()
+		if err := dialect.Lock(conn, lockName, m.LockTimeout); err != nil {
+			return fmt.Errorf("migrate: acquire lock: %w", err)
+		}
+		defer dialect.Unlock(conn, lockName)
+
+		if err := conn.AutoMigrate(&record{}); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+// down откатывает не более steps последних применённых миграций.
+func (m *Migrator) down(conn *gorm.DB, steps int) ([]Migration, error) {
+	var records []record
+	if err := conn.Order("version DESC").Limit(steps).Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, rec := range records {
+		migration := m.find(rec.Version)
+		if migration.Down == nil {
+			return done, fmt.Errorf("migrate: migration %d (%s) cannot be rolled back", rec.Version, rec.Name)
+		}
+		err := conn.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&record{}, rec.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migrate: roll back %d (%s): %w", rec.Version, rec.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// find возвращает миграцию по версии, или пустую миграцию, если её нет в коде.
+func (m *Migrator) find(version int64) Migration {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration
+		}
+	}
+	return Migration{Version: version}
+}
+
+// up применяет миграцию и записывает её в schema_migrations.
+func up(conn *gorm.DB, migration Migration) error {
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if err := migration.Up(tx); err != nil {
+			return err
+		}
+		return tx.Create(&record{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migrate: apply %d (%s): %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// appliedVersions возвращает применённые миграции по версиям.
+func appliedVersions(db *gorm.DB) (map[int64]record, error) {
+	var records []record
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]record, len(records))
+	for _, rec := range records {
+		applied[rec.Version] = rec
+	}
+	return applied, nil
+}
@@ -0,0 +1,234 @@
+package migrate
+
+import (
+	"errors"
+	"os"
+	"server/database"
+	"server/database/dbtest"
+	"strconv"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// widget — таблица, создаваемая тестовыми миграциями.
+type widget struct {
+	ID   uint `gorm:"primaryKey"`
+	Name string
+}
+
+// widgetColor — столбец, добавляемый второй тестовой миграцией.
+type widgetColor struct {
+	Color string
+}
+
+func (widgetColor) TableName() string { return "widgets" }
+
+// testMigrations возвращает миграции, создающие таблицу widgets и добавляющие в неё столбец.
+func testMigrations() []Migration {
+	return []Migration{
+		{
+			Version: 2,
+			Name:    "add_widget_color",
+			Up:      func(tx *gorm.DB) error { return tx.Migrator().AddColumn(&widgetColor{}, "Color") },
+			Down:    func(tx *gorm.DB) error { return tx.Migrator().DropColumn(&widgetColor{}, "Color") },
+		},
+		{
+			Version: 1,
+			Name:    "create_widgets",
+			Up:      func(tx *gorm.DB) error { return tx.Migrator().CreateTable(&widget{}) },
+			Down:    func(tx *gorm.DB) error { return tx.Migrator().DropTable(&widget{}) },
+		},
+	}
+}
+
+// versions возвращает номера версий миграций.
+func versions(migrations []Migration) []int64 {
+	result := make([]int64, len(migrations))
+	for i, migration := range migrations {
+		result[i] = migration.Version
+	}
+	return result
+}
+
+// expectVersions проверяет номера версий миграций.
+func expectVersions(t *testing.T, migrations []Migration, want ...int64) {
+	t.Helper()
+	got := versions(migrations)
+	if len(got) != len(want) {
+		t.Fatalf("expected versions %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected versions %v, got %v", want, got)
+		}
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	db := dbtest.Open(t)
+	m := New(db, testMigrations())
+
+	done, err := m.Up(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectVersions(t, done, 1)
+	if !db.Migrator().HasTable("widgets") || db.Migrator().HasColumn(&widgetColor{}, "Color") {
+		t.Fatal("expected only the first migration to be applied")
+	}
+
+	done, err = m.Up(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectVersions(t, done, 2)
+	if !db.Migrator().HasColumn(&widgetColor{}, "Color") {
+		t.Fatal("expected the second migration to be applied")
+	}
+	if done, err = m.Up(0); err != nil || len(done) != 0 {
+		t.Fatalf("expected nothing to apply, got %v, %v", versions(done), err)
+	}
+
+	done, err = m.Down(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectVersions(t, done, 2)
+	if db.Migrator().HasColumn(&widgetColor{}, "Color") {
+		t.Fatal("expected the second migration to be rolled back")
+	}
+
+	pending, err := m.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectVersions(t, pending, 2)
+
+	done, err = m.Down(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectVersions(t, done, 1)
+	if db.Migrator().HasTable("widgets") {
+		t.Fatal("expected all migrations to be rolled back")
+	}
+}
+
+func TestRedo(t *testing.T) {
+	db := dbtest.Open(t)
+	m := New(db, testMigrations())
+	if _, err := m.Up(0); err != nil {
+		t.Fatal(err)
+	}
+	db.Exec("INSERT INTO widgets (name, color) VALUES ('a', 'red')")
+
+	migration, err := m.Redo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migration == nil || migration.Version != 2 {
+		t.Fatalf("expected migration 2 to be redone, got %+v", migration)
+	}
+	var color string
+	db.Raw("SELECT color FROM widgets").Scan(&color)
+	if color != "" {
+		t.Fatalf("expected the column to be recreated, got color %q", color)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	db := dbtest.Open(t)
+	if _, err := New(db, testMigrations()).Up(0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Миграция 2 применена, но удалена из кода; миграция 3 ещё не применена
+	m := New(db, []Migration{testMigrations()[1], {Version: 3, Name: "later", Up: func(*gorm.DB) error { return nil }}})
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 3 {
+		t.Fatalf("expected 3 statuses, got %+v", statuses)
+	}
+	if s := statuses[0]; s.Version != 1 || s.AppliedAt == nil || s.Missing {
+		t.Errorf("unexpected status of migration 1: %+v", s)
+	}
+	if s := statuses[1]; s.Version != 2 || s.AppliedAt == nil || !s.Missing || s.Name != "add_widget_color" {
+		t.Errorf("unexpected status of migration 2: %+v", s)
+	}
+	if s := statuses[2]; s.Version != 3 || s.AppliedAt != nil {
+		t.Errorf("unexpected status of migration 3: %+v", s)
+	}
+
+	if _, err := m.Down(1); err == nil {
+		t.Fatal("expected error rolling back a migration missing from code")
+	}
+}
+
+func TestFailedMigrationIsRolledBack(t *testing.T) {
+	db := dbtest.Open(t)
+	failure := errors.New("boom")
+	m := New(db, append(testMigrations(), Migration{
+		Version: 3,
+		Name:    "broken",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec("INSERT INTO widgets (name) VALUES ('partial')").Error; err != nil {
+				return err
+			}
+			return failure
+		},
+	}))
+
+	done, err := m.Up(0)
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected migration error, got %v", err)
+	}
+	expectVersions(t, done, 1, 2)
+
+	var count int64
+	db.Raw("SELECT COUNT(*) FROM widgets").Scan(&count)
+	if count != 0 {
+		t.Fatal("changes of the failed migration must be rolled back")
+	}
+	pending, err := m.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectVersions(t, pending, 3)
+}
+
+func TestDuplicateVersionPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for duplicate versions")
+		}
+	}()
+	New(nil, append(testMigrations(), Migration{Version: 1, Name: "again"}))
+}
+
+func TestUpWaitsForLock(t *testing.T) {
+	db := dbtest.Open(t)
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skip("hostname is unavailable")
+	}
+
+	// Блокировку держит живой процесс на этом хосте, даже если миграция идёт давно
+	if err := database.Current().Lock(db, "probe", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	owner := host + ":" + strconv.Itoa(os.Getppid())
+	db.Exec("INSERT INTO schema_locks (name, locked_at, owner) VALUES (?, ?, ?)", lockName, time.Now().UTC().Add(-time.Hour), owner)
+
+	m := New(db, testMigrations())
+	m.LockTimeout = 100 * time.Millisecond
+	if _, err := m.Up(0); !errors.Is(err, database.ErrLockTimeout) {
+		t.Fatalf("expected ErrLockTimeout, got %v", err)
+	}
+	if db.Migrator().HasTable("widgets") {
+		t.Fatal("migrations must not run without the lock")
+	}
+}
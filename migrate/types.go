@@ -0,0 +1,36 @@
+// Package migrate применяет и откатывает версионированные миграции схемы базы данных.
+package migrate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration описывает одну миграцию схемы.
+type Migration struct {
+	Version int64                // Номер версии; миграции применяются по возрастанию
+	Name    string               // Краткое описание, например "create_beers"
+	Up      func(*gorm.DB) error // Применение миграции
+	Down    func(*gorm.DB) error // Откат миграции
+}
+
+// Status описывает состояние миграции в базе данных.
+type Status struct {
+	Version   int64      // Номер версии
+	Name      string     // Описание миграции
+	AppliedAt *time.Time // Время применения; nil, если миграция не применена
+	Missing   bool       // Миграция применена, но отсутствует в коде
+}
+
+// record — строка таблицы schema_migrations.
+type record struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"` // Номер версии
+	Name      string    `gorm:"type:varchar(255);not null"`     // Описание миграции
+	AppliedAt time.Time `gorm:"not null"`                       // Время применения
+}
+
+// TableName возвращает имя таблицы применённых миграций.
+func (record) TableName() string {
+	return "schema_migrations"
+}
@@ -0,0 +1,135 @@
+// Package migrations содержит миграции схемы базы данных приложения.
+package migrations
+
+import (
+	"server/migrate"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Структуры ниже фиксируют схему на момент миграции и не должны меняться вместе с моделями.
+// Для баз, созданных до появления миграций через AutoMigrate, миграция ничего не изменяет.
+
+type beer struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	Name        string         `gorm:"type:varchar(100);not null"`
+	Brewery     string         `gorm:"type:varchar(100)"`
+	Style       string         `gorm:"type:varchar(50)"`
+	Alcohol     float32        `gorm:"type:float"`
+	Description string         `gorm:"type:text"`
+	IBU         int
+	EBC         int
+}
+
+type snack struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	Name        string         `gorm:"type:varchar(100);not null"`
+	Type        string         `gorm:"type:varchar(50)"`
+	Description string         `gorm:"type:text"`
+	Country     string         `gorm:"type:varchar(50)"`
+	Calories    int
+	Spicy       bool
+	Vegetarian  bool
+}
+
+type session struct {
+	ID        string `gorm:"type:varchar(64);primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Data      string    `gorm:"type:text"`
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+type permission struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"type:varchar(100);not null;unique"`
+}
+
+type role struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Name        string       `gorm:"type:varchar(50);not null;unique"`
+	Permissions []permission `gorm:"many2many:role_permissions"`
+}
+
+type apiKey struct {
+	ID         uint `gorm:"primaryKey"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Name       string `gorm:"type:varchar(100);not null"`
+	Prefix     string `gorm:"type:varchar(16);not null"`
+	Hash       string `gorm:"type:char(64);not null;unique"`
+	Secret     string `gorm:"type:varchar(64)"`
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time `gorm:"index"`
+	Roles      []role     `gorm:"many2many:api_key_roles"`
+}
+
+type user struct {
+	ID           uint `gorm:"primaryKey"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	Email        string         `gorm:"type:varchar(255);not null;unique"`
+	Name         string         `gorm:"type:varchar(100)"`
+	PasswordHash string         `gorm:"type:varchar(255);not null"`
+	Roles        []role         `gorm:"many2many:user_roles"`
+}
+
+type refreshToken struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UserID    uint   `gorm:"not null;index"`
+	FamilyID  string `gorm:"type:varchar(64);not null;index"`
+	JTI       string `gorm:"type:varchar(64);not null;unique"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+type auditEntry struct {
+	ID        uint      `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"index"`
+	ActorKind string    `gorm:"type:varchar(20)"`
+	ActorID   uint      `gorm:"index"`
+	ActorName string    `gorm:"type:varchar(255)"`
+	Action    string    `gorm:"type:varchar(20);not null;index"`
+	Entity    string    `gorm:"type:varchar(50);not null;index:idx_audit_entity"`
+	EntityID  uint      `gorm:"index:idx_audit_entity"`
+	Changes   []byte    `gorm:"type:text"`
+	RequestID string    `gorm:"type:varchar(128);index"`
+}
+
+func init() {
+	register(migrate.Migration{
+		Version: 1,
+		Name:    "initial_schema",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(
+				&beer{}, &snack{}, &session{}, &permission{}, &role{},
+				&apiKey{}, &user{}, &refreshToken{}, &auditEntry{},
+			)
+		},
+		Down: func(tx *gorm.DB) error {
+			tables := []string{
+				"audit_entries", "refresh_tokens", "user_roles", "users",
+				"api_key_roles", "api_keys", "role_permissions", "roles",
+				"permissions", "sessions", "snacks", "beers",
+			}
+			for _, table := range tables {
+				if err := tx.Migrator().DropTable(table); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
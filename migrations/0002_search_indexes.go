@@ -0,0 +1,30 @@
+// Package migrations содержит миграции схемы базы данных приложения.
+package migrations
+
+import (
+	"server/database"
+	"server/migrate"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	register(migrate.Migration{
+		Version: 2,
+		Name:    "search_indexes",
+		Up: func(tx *gorm.DB) error {
+			dialect := database.Current()
+			if err := dialect.EnsureSearchIndex(tx, "beers", "name", "brewery", "style", "description"); err != nil {
+				return err
+			}
+			return dialect.EnsureSearchIndex(tx, "snacks", "name", "type", "description", "country")
+		},
+		Down: func(tx *gorm.DB) error {
+			dialect := database.Current()
+			if err := dialect.DropSearchIndex(tx, "beers"); err != nil {
+				return err
+			}
+			return dialect.DropSearchIndex(tx, "snacks")
+		},
+	})
+}
@@ -0,0 +1,48 @@
+// Package migrations содержит миграции схемы базы данных приложения.
+package migrations
+
+import (
+	"server/migrate"
+
+	"gorm.io/gorm"
+)
+
+// Столбцы версии для оптимистической блокировки пива и закусок.
+
+type beerVersion struct {
+	Version uint `gorm:"not null;default:1"`
+}
+
+func (beerVersion) TableName() string { return "beers" }
+
+type snackVersion struct {
+	Version uint `gorm:"not null;default:1"`
+}
+
+func (snackVersion) TableName() string { return "snacks" }
+
+func init() {
+	register(migrate.Migration{
+		Version: 3,
+		Name:    "version_columns",
+		Up: func(tx *gorm.DB) error {
+			for _, model := range []any{&beerVersion{}, &snackVersion{}} {
+				if tx.Migrator().HasColumn(model, "Version") {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(model, "Version"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, model := range []any{&beerVersion{}, &snackVersion{}} {
+				if err := tx.Migrator().DropColumn(model, "Version"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,19 @@
+// Package migrations содержит миграции схемы базы данных приложения.
+// Каждая миграция находится в отдельном файле с номером версии в имени
+// и регистрируется в init() этого файла.
+package migrations
+
+import "server/migrate"
+
+// all содержит зарегистрированные миграции.
+var all []migrate.Migration
+
+// register добавляет миграцию в список. Вызывается из init() файлов с миграциями.
+func register(m migrate.Migration) {
+	all = append(all, m)
+}
+
+// All возвращает все миграции приложения.
+func All() []migrate.Migration {
+	return all
+}
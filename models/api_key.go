@@ -0,0 +1,86 @@
+// Package models содержит определения моделей данных и функции для работы с ними.
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIKey представляет ключ доступа к API. Сам ключ не хранится, хранится только его хеш.
+// Ключ подписи запросов хранит также секрет HMAC: для проверки подписи он нужен серверу в открытом виде.
+type APIKey struct {
+	ID        uint      `gorm:"primaryKey"` // Уникальный идентификатор
+	CreatedAt time.Time // Время создания записи
+	UpdatedAt time.Time // Время последнего обновления записи
+
+	Name       string     `gorm:"type:varchar(100);not null"`    // Название ключа (владелец, интеграция)
+	Prefix     string     `gorm:"type:varchar(16);not null"`     // Начало ключа для отображения
+	Hash       string     `gorm:"type:char(64);not null;unique"` // SHA-256 хеш ключа
+	Secret     string     `gorm:"type:varchar(64)"`              // Секрет подписи запросов; пусто, если подпись не используется
+	LastUsedAt *time.Time // Время последнего использования
+	RevokedAt  *time.Time `gorm:"index"`                   // Время отзыва ключа
+	Roles      []Role     `gorm:"many2many:api_key_roles"` // Роли ключа
+}
+
+// CreateAPIKey сохраняет новую запись ключа в базе данных.
+func CreateAPIKey(db *gorm.DB, key *APIKey) error {
+	return db.Create(key).Error
+}
+
+// GetActiveAPIKeyByHash возвращает неотозванный ключ по хешу.
+func GetActiveAPIKeyByHash(db *gorm.DB, hash string) (*APIKey, error) {
+	var key APIKey
+	if err := db.Where("hash = ? AND revoked_at IS NULL", hash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetActiveSigningKey возвращает неотозванный ключ подписи запросов по идентификатору.
+func GetActiveSigningKey(db *gorm.DB, id uint) (*APIKey, error) {
+	var key APIKey
+	if err := db.Where("id = ? AND revoked_at IS NULL AND secret <> ''", id).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetAllAPIKeys возвращает список всех ключей, включая отозванные.
+func GetAllAPIKeys(db *gorm.DB) ([]APIKey, error) {
+	var keys []APIKey
+	if err := db.Order("id").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// GetAPIKeyByID возвращает ключ по идентификатору.
+func GetAPIKeyByID(db *gorm.DB, id uint) (*APIKey, error) {
+	var key APIKey
+	if err := db.First(&key, id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// RevokeAPIKey отзывает ключ по идентификатору.
+// Возвращает gorm.ErrRecordNotFound, если активного ключа с таким ID нет.
+func RevokeAPIKey(db *gorm.DB, id uint) error {
+	result := db.Model(&APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// TouchAPIKey обновляет время последнего использования ключа,
+// не изменяя UpdatedAt.
+func TouchAPIKey(db *gorm.DB, id uint, at time.Time) error {
+	return db.Model(&APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
@@ -0,0 +1,76 @@
+// Package models содержит определения моделей данных и функции для работы с ними.
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditEntry представляет запись журнала изменений данных.
+type AuditEntry struct {
+	ID        uint      `gorm:"primaryKey"` // Уникальный идентификатор
+	CreatedAt time.Time `gorm:"index"`      // Время изменения
+
+	ActorKind string          `gorm:"type:varchar(20)"`                                 // Тип субъекта: "user", "api_key" или пусто для анонимного
+	ActorID   uint            `gorm:"index"`                                            // Идентификатор субъекта
+	ActorName string          `gorm:"type:varchar(255)"`                                // Имя субъекта на момент изменения
+	Action    string          `gorm:"type:varchar(20);not null;index"`                  // Действие: create, update, delete
+	Entity    string          `gorm:"type:varchar(50);not null;index:idx_audit_entity"` // Тип сущности, например "beer"
+	EntityID  uint            `gorm:"index:idx_audit_entity"`                           // Идентификатор сущности
+	Changes   json.RawMessage `gorm:"type:text"`                                        // Изменённые поля: {"поле": {"before": ..., "after": ...}}
+	RequestID string          `gorm:"type:varchar(128);index"`                          // Идентификатор HTTP-запроса
+}
+
+// AuditFilter задаёт условия выборки записей журнала. Пустые поля не учитываются.
+type AuditFilter struct {
+	Entity    string
+	EntityID  uint
+	Action    string
+	ActorKind string
+	ActorID   uint
+	RequestID string
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Offset    int
+}
+
+// CreateAuditEntry сохраняет запись журнала изменений.
+func CreateAuditEntry(db *gorm.DB, entry *AuditEntry) error {
+	return db.Create(entry).Error
+}
+
+// GetAuditEntries возвращает записи журнала по фильтру, начиная с самых новых.
+func GetAuditEntries(db *gorm.DB, filter AuditFilter) ([]AuditEntry, error) {
+	query := db.Model(&AuditEntry{})
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ActorKind != "" {
+		query = query.Where("actor_kind = ?", filter.ActorKind)
+	}
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	var entries []AuditEntry
+	err := query.Order("id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&entries).Error
+	return entries, err
+}
@@ -3,6 +3,7 @@ package models
 
 import (
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"time"
 )
 
@@ -11,7 +12,8 @@ type Beer struct {
 	ID        uint           `gorm:"primaryKey"` // Уникальный идентификатор
 	CreatedAt time.Time      // Время создания записи
 	UpdatedAt time.Time      // Время последнего обновления записи
-	DeletedAt gorm.DeletedAt `gorm:"index"` // Время удаления записи (soft delete)
+	DeletedAt gorm.DeletedAt `gorm:"index"`              // Время удаления записи (soft delete)
+	Version   uint           `gorm:"not null;default:1"` // Версия записи, увеличивается при каждом сохранении
 
 	Name        string  `gorm:"type:varchar(100);not null"` // Название пива
 	Brewery     string  `gorm:"type:varchar(100)"`          // Пивоварня
@@ -22,8 +24,26 @@ type Beer struct {
 	EBC         int     `gorm:""`                           // Цвет (European Brewery Convention)
 }
 
-// CreateBeer сохраняет новую запись пива в базе данных.
+// BeerSearchColumns — столбцы, по которым выполняется полнотекстовый поиск пива.
+var BeerSearchColumns = []string{"name", "brewery", "style", "description"}
+
+// Validate проверяет поля пива перед сохранением.
+// Возвращает ValidationErrors со списком нарушенных правил.
+func (b *Beer) Validate() error {
+	var v validator
+	v.required("Name", b.Name)
+	v.maxLength("Name", b.Name, 100)
+	v.maxLength("Brewery", b.Brewery, 100)
+	v.maxLength("Style", b.Style, 50)
+	v.between("Alcohol", float64(b.Alcohol), 0, 100)
+	v.atLeast("IBU", float64(b.IBU), 0)
+	v.atLeast("EBC", float64(b.EBC), 0)
+	return v.err()
+}
+
+// CreateBeer сохраняет новую запись пива в базе данных с версией 1.
 func CreateBeer(db *gorm.DB, beer *Beer) error {
+	beer.Version = 1
 	return db.Create(beer).Error
 }
 
@@ -45,12 +65,64 @@ func GetAllBeers(db *gorm.DB) ([]Beer, error) {
 	return beers, nil
 }
 
-// UpdateBeer обновляет существующую запись пива в базе данных.
+// UpdateBeer обновляет существующую запись пива в базе данных, если её версия
+// совпадает с beer.Version, и увеличивает версию.
+// Возвращает ErrVersionConflict, если запись изменена другим запросом.
 func UpdateBeer(db *gorm.DB, beer *Beer) error {
-	return db.Save(beer).Error
+	return saveVersioned(db, beer, &beer.Version)
 }
 
 // DeleteBeer удаляет запись пива по идентификатору.
 func DeleteBeer(db *gorm.DB, id uint) error {
 	return db.Delete(&Beer{}, id).Error
 }
+
+// GetDeletedBeers возвращает мягко удалённые записи пива, начиная с последних удалённых.
+func GetDeletedBeers(db *gorm.DB) ([]Beer, error) {
+	var beers []Beer
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&beers).Error; err != nil {
+		return nil, err
+	}
+	return beers, nil
+}
+
+// GetDeletedBeerByID возвращает мягко удалённую запись пива по идентификатору.
+func GetDeletedBeerByID(db *gorm.DB, id uint) (*Beer, error) {
+	var beer Beer
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL").First(&beer, id).Error; err != nil {
+		return nil, err
+	}
+	return &beer, nil
+}
+
+// RestoreBeer восстанавливает мягко удалённую запись пива и увеличивает её версию.
+func RestoreBeer(db *gorm.DB, id uint) error {
+	return restore(db, &Beer{}, id)
+}
+
+// ForceDeleteBeer окончательно удаляет запись пива, в том числе находящуюся в корзине.
+func ForceDeleteBeer(db *gorm.DB, id uint) error {
+	return db.Unscoped().Delete(&Beer{}, id).Error
+}
+
+// PurgeBeers окончательно удаляет записи пива, мягко удалённые раньше before,
+// и возвращает удалённые записи.
+func PurgeBeers(db *gorm.DB, before time.Time) ([]Beer, error) {
+	var beers []Beer
+	if err := db.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Find(&beers).Error; err != nil {
+		return nil, err
+	}
+	if len(beers) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, len(beers))
+	for i := range beers {
+		ids[i] = beers[i].ID
+	}
+	if err := db.Unscoped().Delete(&Beer{}, ids).Error; err != nil {
+		return nil, err
+	}
+	return beers, nil
+}
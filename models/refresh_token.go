@@ -0,0 +1,53 @@
+// Package models содержит определения моделей данных и функции для работы с ними.
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken представляет выданный refresh-токен. Токены одной цепочки ротации
+// объединены общим FamilyID, что позволяет отозвать всю цепочку при повторном использовании.
+type RefreshToken struct {
+	ID        uint      `gorm:"primaryKey"` // Уникальный идентификатор
+	CreatedAt time.Time // Время создания записи
+
+	UserID    uint       `gorm:"not null;index"`                   // Владелец токена
+	FamilyID  string     `gorm:"type:varchar(64);not null;index"`  // Цепочка ротации
+	JTI       string     `gorm:"type:varchar(64);not null;unique"` // Идентификатор токена (claim jti)
+	ExpiresAt time.Time  // Время истечения токена
+	UsedAt    *time.Time // Время обмена на новую пару токенов
+	RevokedAt *time.Time // Время отзыва токена
+}
+
+// CreateRefreshToken сохраняет новую запись refresh-токена.
+func CreateRefreshToken(db *gorm.DB, token *RefreshToken) error {
+	return db.Create(token).Error
+}
+
+// GetRefreshTokenByJTI возвращает refresh-токен по его идентификатору jti.
+func GetRefreshTokenByJTI(db *gorm.DB, jti string) (*RefreshToken, error) {
+	var token RefreshToken
+	if err := db.Where("jti = ?", jti).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// UseRefreshToken атомарно отмечает токен использованным.
+// Возвращает false, если токен уже был использован, отозван или истёк.
+func UseRefreshToken(db *gorm.DB, jti string) (bool, error) {
+	now := time.Now()
+	result := db.Model(&RefreshToken{}).
+		Where("jti = ? AND used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", jti, now).
+		Update("used_at", now)
+	return result.RowsAffected == 1, result.Error
+}
+
+// RevokeRefreshTokenFamily отзывает все токены цепочки ротации familyID.
+func RevokeRefreshTokenFamily(db *gorm.DB, familyID string) error {
+	return db.Model(&RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
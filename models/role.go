@@ -0,0 +1,110 @@
+// Package models содержит определения моделей данных и функции для работы с ними.
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Role представляет роль — именованный набор разрешений.
+type Role struct {
+	ID        uint      `gorm:"primaryKey"` // Уникальный идентификатор
+	CreatedAt time.Time // Время создания записи
+	UpdatedAt time.Time // Время последнего обновления записи
+
+	Name        string       `gorm:"type:varchar(50);not null;unique"` // Название роли, например "editor"
+	Permissions []Permission `gorm:"many2many:role_permissions"`       // Разрешения роли
+}
+
+// Permission представляет разрешение на действие, например "beer:delete".
+// Разрешение "*" даёт доступ ко всему, "beer:*" — ко всем действиям с пивом.
+type Permission struct {
+	ID   uint   `gorm:"primaryKey"`                        // Уникальный идентификатор
+	Name string `gorm:"type:varchar(100);not null;unique"` // Название разрешения
+}
+
+// EnsureRole создаёт роль name, если её нет, и добавляет ей недостающие разрешения.
+func EnsureRole(db *gorm.DB, name string, permissions ...string) (*Role, error) {
+	var role Role
+	if err := db.Where(Role{Name: name}).FirstOrCreate(&role).Error; err != nil {
+		return nil, err
+	}
+	for _, permission := range permissions {
+		if err := GrantPermission(db, &role, permission); err != nil {
+			return nil, err
+		}
+	}
+	return &role, nil
+}
+
+// GrantPermission добавляет роли разрешение, создавая его при необходимости.
+func GrantPermission(db *gorm.DB, role *Role, permission string) error {
+	var p Permission
+	if err := db.Where(Permission{Name: permission}).FirstOrCreate(&p).Error; err != nil {
+		return err
+	}
+	return db.Model(role).Association("Permissions").Append(&p)
+}
+
+// GetRoleByName возвращает роль по названию вместе с её разрешениями.
+func GetRoleByName(db *gorm.DB, name string) (*Role, error) {
+	var role Role
+	if err := db.Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// GetAllRoles возвращает список всех ролей с их разрешениями.
+func GetAllRoles(db *gorm.DB) ([]Role, error) {
+	var roles []Role
+	if err := db.Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// AssignUserRole назначает пользователю роль.
+func AssignUserRole(db *gorm.DB, user *User, role *Role) error {
+	return db.Model(user).Association("Roles").Append(role)
+}
+
+// UnassignUserRole снимает с пользователя роль.
+func UnassignUserRole(db *gorm.DB, user *User, role *Role) error {
+	return db.Model(user).Association("Roles").Delete(role)
+}
+
+// AssignAPIKeyRole назначает ключу API роль.
+func AssignAPIKeyRole(db *gorm.DB, key *APIKey, role *Role) error {
+	return db.Model(key).Association("Roles").Append(role)
+}
+
+// UnassignAPIKeyRole снимает с ключа API роль.
+func UnassignAPIKeyRole(db *gorm.DB, key *APIKey, role *Role) error {
+	return db.Model(key).Association("Roles").Delete(role)
+}
+
+// GetUserPermissions возвращает названия всех разрешений пользователя по его ролям.
+func GetUserPermissions(db *gorm.DB, userID uint) ([]string, error) {
+	var names []string
+	err := db.Model(&Permission{}).
+		Distinct("permissions.name").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Pluck("permissions.name", &names).Error
+	return names, err
+}
+
+// GetAPIKeyPermissions возвращает названия всех разрешений ключа API по его ролям.
+func GetAPIKeyPermissions(db *gorm.DB, keyID uint) ([]string, error) {
+	var names []string
+	err := db.Model(&Permission{}).
+		Distinct("permissions.name").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN api_key_roles ON api_key_roles.role_id = role_permissions.role_id").
+		Where("api_key_roles.api_key_id = ?", keyID).
+		Pluck("permissions.name", &names).Error
+	return names, err
+}
@@ -0,0 +1,43 @@
+// Package models содержит определения моделей данных и функции для работы с ними.
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Session представляет серверную сессию клиента, хранящуюся в базе данных.
+type Session struct {
+	ID        string    `gorm:"type:varchar(64);primaryKey"` // Идентификатор сессии
+	CreatedAt time.Time // Время создания записи
+	UpdatedAt time.Time // Время последнего обновления записи
+
+	Data      string    `gorm:"type:text"` // Данные сессии в формате JSON
+	ExpiresAt time.Time `gorm:"index"`     // Время истечения сессии
+}
+
+// GetSessionByID возвращает действующую (не истёкшую) сессию по идентификатору.
+func GetSessionByID(db *gorm.DB, id string) (*Session, error) {
+	var session Session
+	if err := db.Where("id = ? AND expires_at > ?", id, time.Now()).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// SaveSession создаёт или обновляет запись сессии.
+func SaveSession(db *gorm.DB, session *Session) error {
+	return db.Save(session).Error
+}
+
+// DeleteSession удаляет сессию по идентификатору.
+func DeleteSession(db *gorm.DB, id string) error {
+	return db.Delete(&Session{}, "id = ?", id).Error
+}
+
+// DeleteExpiredSessions удаляет все истёкшие сессии и возвращает их количество.
+func DeleteExpiredSessions(db *gorm.DB) (int64, error) {
+	result := db.Where("expires_at <= ?", time.Now()).Delete(&Session{})
+	return result.RowsAffected, result.Error
+}
@@ -3,6 +3,7 @@ package models
 
 import (
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"time"
 )
 
@@ -11,7 +12,8 @@ type Snack struct {
 	ID        uint           `gorm:"primaryKey"` // Уникальный идентификатор
 	CreatedAt time.Time      // Время создания записи
 	UpdatedAt time.Time      // Время последнего обновления записи
-	DeletedAt gorm.DeletedAt `gorm:"index"` // Время удаления записи (soft delete)
+	DeletedAt gorm.DeletedAt `gorm:"index"`              // Время удаления записи (soft delete)
+	Version   uint           `gorm:"not null;default:1"` // Версия записи, увеличивается при каждом сохранении
 
 	Name        string `gorm:"type:varchar(100);not null"` // Название закуски
 	Type        string `gorm:"type:varchar(50)"`           // Тип закуски
@@ -22,8 +24,24 @@ type Snack struct {
 	Vegetarian  bool   `gorm:""`                           // Вегетарианская ли закуска
 }
 
-// CreateSnack сохраняет новую запись закуски в базе данных.
+// SnackSearchColumns — столбцы, по которым выполняется полнотекстовый поиск закусок.
+var SnackSearchColumns = []string{"name", "type", "description", "country"}
+
+// Validate проверяет поля закуски перед сохранением.
+// Возвращает ValidationErrors со списком нарушенных правил.
+func (s *Snack) Validate() error {
+	var v validator
+	v.required("Name", s.Name)
+	v.maxLength("Name", s.Name, 100)
+	v.maxLength("Type", s.Type, 50)
+	v.maxLength("Country", s.Country, 50)
+	v.atLeast("Calories", float64(s.Calories), 0)
+	return v.err()
+}
+
+// CreateSnack сохраняет новую запись закуски в базе данных с версией 1.
 func CreateSnack(db *gorm.DB, snack *Snack) error {
+	snack.Version = 1
 	return db.Create(snack).Error
 }
 
@@ -45,12 +63,64 @@ func GetAllSnacks(db *gorm.DB) ([]Snack, error) {
 	return snacks, nil
 }
 
-// UpdateSnack обновляет существующую запись закуски в базе данных.
+// UpdateSnack обновляет существующую запись закуски в базе данных, если её версия
+// совпадает с snack.Version, и увеличивает версию.
+// Возвращает ErrVersionConflict, если запись изменена другим запросом.
 func UpdateSnack(db *gorm.DB, snack *Snack) error {
-	return db.Save(snack).Error
+	return saveVersioned(db, snack, &snack.Version)
 }
 
 // DeleteSnack удаляет запись закуски по идентификатору.
 func DeleteSnack(db *gorm.DB, id uint) error {
 	return db.Delete(&Snack{}, id).Error
 }
+
+// GetDeletedSnacks возвращает мягко удалённые записи закусок, начиная с последних удалённых.
+func GetDeletedSnacks(db *gorm.DB) ([]Snack, error) {
+	var snacks []Snack
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&snacks).Error; err != nil {
+		return nil, err
+	}
+	return snacks, nil
+}
+
+// GetDeletedSnackByID возвращает мягко удалённую запись закуски по идентификатору.
+func GetDeletedSnackByID(db *gorm.DB, id uint) (*Snack, error) {
+	var snack Snack
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL").First(&snack, id).Error; err != nil {
+		return nil, err
+	}
+	return &snack, nil
+}
+
+// RestoreSnack восстанавливает мягко удалённую запись закуски и увеличивает её версию.
+func RestoreSnack(db *gorm.DB, id uint) error {
+	return restore(db, &Snack{}, id)
+}
+
+// ForceDeleteSnack окончательно удаляет запись закуски, в том числе находящуюся в корзине.
+func ForceDeleteSnack(db *gorm.DB, id uint) error {
+	return db.Unscoped().Delete(&Snack{}, id).Error
+}
+
+// PurgeSnacks окончательно удаляет записи закусок, мягко удалённые раньше before,
+// и возвращает удалённые записи.
+func PurgeSnacks(db *gorm.DB, before time.Time) ([]Snack, error) {
+	var snacks []Snack
+	if err := db.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Find(&snacks).Error; err != nil {
+		return nil, err
+	}
+	if len(snacks) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, len(snacks))
+	for i := range snacks {
+		ids[i] = snacks[i].ID
+	}
+	if err := db.Unscoped().Delete(&Snack{}, ids).Error; err != nil {
+		return nil, err
+	}
+	return snacks, nil
+}
@@ -0,0 +1,49 @@
+// Package models содержит определения моделей данных и функции для работы с ними.
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// User представляет учётную запись пользователя API.
+type User struct {
+	ID        uint           `gorm:"primaryKey"` // Уникальный идентификатор
+	CreatedAt time.Time      // Время создания записи
+	UpdatedAt time.Time      // Время последнего обновления записи
+	DeletedAt gorm.DeletedAt `gorm:"index"` // Время удаления записи (soft delete)
+
+	Email        string `gorm:"type:varchar(255);not null;unique"`   // Адрес электронной почты (логин)
+	Name         string `gorm:"type:varchar(100)"`                   // Отображаемое имя
+	PasswordHash string `gorm:"type:varchar(255);not null" json:"-"` // Хеш пароля (argon2id или bcrypt)
+	Roles        []Role `gorm:"many2many:user_roles"`                // Роли пользователя
+}
+
+// CreateUser сохраняет новую запись пользователя в базе данных.
+func CreateUser(db *gorm.DB, user *User) error {
+	return db.Create(user).Error
+}
+
+// GetUserByID возвращает пользователя по его идентификатору.
+func GetUserByID(db *gorm.DB, id uint) (*User, error) {
+	var user User
+	if err := db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserByEmail возвращает пользователя по адресу электронной почты.
+func GetUserByEmail(db *gorm.DB, email string) (*User, error) {
+	var user User
+	if err := db.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateUserPassword заменяет хеш пароля пользователя.
+func UpdateUserPassword(db *gorm.DB, id uint, hash string) error {
+	return db.Model(&User{}).Where("id = ?", id).Update("password_hash", hash).Error
+}
@@ -0,0 +1,103 @@
+// Package models содержит определения моделей данных и функции для работы с ними.
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError описывает нарушение правила валидации одного поля модели.
+type ValidationError struct {
+	Field string // Имя поля
+	Rule  string // Нарушенное правило: "required", "max_length", "min", "max"
+	Param any    // Параметр правила, например максимальная длина
+}
+
+// Error возвращает текстовое описание ошибки валидации.
+func (e ValidationError) Error() string {
+	switch e.Rule {
+	case "required":
+		return fmt.Sprintf("%s is required", e.Field)
+	case "max_length":
+		return fmt.Sprintf("%s must be at most %v characters", e.Field, e.Param)
+	case "min":
+		return fmt.Sprintf("%s must be at least %v", e.Field, e.Param)
+	case "max":
+		return fmt.Sprintf("%s must be at most %v", e.Field, e.Param)
+	}
+	return fmt.Sprintf("%s is invalid", e.Field)
+}
+
+// Localize возвращает описание ошибки, переведённое функцией t.
+// Используются ключи каталога "validation.<правило>" и "field.<поле>".
+func (e ValidationError) Localize(t func(key string, args ...any) string) string {
+	if e.Param != nil {
+		return t("validation."+e.Rule, t("field."+e.Field), e.Param)
+	}
+	return t("validation."+e.Rule, t("field."+e.Field))
+}
+
+// ValidationErrors — список ошибок валидации модели.
+type ValidationErrors []ValidationError
+
+// Error объединяет описания всех ошибок валидации.
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Localize объединяет переведённые описания всех ошибок валидации.
+func (e ValidationErrors) Localize(t func(key string, args ...any) string) string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Localize(t)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// validator накапливает ошибки валидации полей.
+type validator struct {
+	errors ValidationErrors
+}
+
+// required проверяет, что строковое поле не пустое.
+func (v *validator) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.errors = append(v.errors, ValidationError{Field: field, Rule: "required"})
+	}
+}
+
+// maxLength проверяет, что длина строкового поля в символах не превышает max.
+func (v *validator) maxLength(field, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		v.errors = append(v.errors, ValidationError{Field: field, Rule: "max_length", Param: max})
+	}
+}
+
+// between проверяет, что числовое поле находится в диапазоне [min, max].
+func (v *validator) between(field string, value, min, max float64) {
+	if value < min {
+		v.errors = append(v.errors, ValidationError{Field: field, Rule: "min", Param: min})
+	} else if value > max {
+		v.errors = append(v.errors, ValidationError{Field: field, Rule: "max", Param: max})
+	}
+}
+
+// atLeast проверяет, что числовое поле не меньше min.
+func (v *validator) atLeast(field string, value, min float64) {
+	if value < min {
+		v.errors = append(v.errors, ValidationError{Field: field, Rule: "min", Param: min})
+	}
+}
+
+// err возвращает накопленные ошибки или nil, если их нет.
+func (v *validator) err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return v.errors
+}
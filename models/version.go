@@ -0,0 +1,40 @@
+// Package models содержит определения моделей данных и функции для работы с ними.
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict возвращается при сохранении записи, которую после чтения
+// изменил или удалил другой запрос.
+var ErrVersionConflict = errors.New("version conflict")
+
+// saveVersioned сохраняет все поля записи model, только если её версия в базе равна *version,
+// и увеличивает версию. При ошибке *version остаётся прежней.
+func saveVersioned(db *gorm.DB, model any, version *uint) error {
+	expected := *version
+	*version = expected + 1
+
+	result := db.Model(model).Where("version = ?", expected).
+		Select("*").Omit("id", "created_at", "deleted_at").Updates(model)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error != nil {
+		*version = expected
+	}
+	return result.Error
+}
+
+// restore снимает отметку мягкого удаления с записи model по идентификатору id
+// и увеличивает её версию. Возвращает gorm.ErrRecordNotFound, если запись не в корзине.
+func restore(db *gorm.DB, model any, id uint) error {
+	result := db.Unscoped().Model(model).Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]any{"deleted_at": nil, "version": gorm.Expr("version + 1")})
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
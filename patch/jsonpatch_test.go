@@ -0,0 +1,153 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// assertJSON сравнивает JSON-документы got и want без учёта форматирования и порядка ключей.
+func assertJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("invalid result %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("invalid expectation %s: %v", want, err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string // Ожидаемый документ; пусто, если ожидается ошибка
+		err   error  // Ожидаемая ошибка, если её можно указать точно
+	}{
+		// Примеры из приложения A RFC 6902
+		{"A.1 add object member", `{"foo":"bar"}`,
+			`[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`, nil},
+		{"A.2 add array element", `{"foo":["bar","baz"]}`,
+			`[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`, nil},
+		{"A.3 remove object member", `{"baz":"qux","foo":"bar"}`,
+			`[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`, nil},
+		{"A.4 remove array element", `{"foo":["bar","qux","baz"]}`,
+			`[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`, nil},
+		{"A.5 replace value", `{"baz":"qux","foo":"bar"}`,
+			`[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`, nil},
+		{"A.6 move value", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`, nil},
+		{"A.7 move array element", `{"foo":["all","grass","cows","eat"]}`,
+			`[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`, nil},
+		{"A.8 test success", `{"baz":"qux","foo":["a",2,"c"]}`,
+			`[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`, nil},
+		{"A.9 test failure", `{"baz":"qux"}`,
+			`[{"op":"test","path":"/baz","value":"bar"}]`, "", ErrTestFailed},
+		{"A.10 add nested member object", `{"foo":"bar"}`,
+			`[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`, nil},
+		{"A.11 ignore unrecognized elements", `{"foo":"bar"}`,
+			`[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, `{"foo":"bar","baz":"qux"}`, nil},
+		{"A.12 add to nonexistent target", `{"foo":"bar"}`,
+			`[{"op":"add","path":"/baz/bat","value":"qux"}]`, "", ErrPathNotFound},
+		{"A.14 ~ escape ordering", `{"/":9,"~1":10}`,
+			`[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`, nil},
+		{"A.15 compare strings and numbers", `{"/":9,"~1":10}`,
+			`[{"op":"test","path":"/~01","value":"10"}]`, "", ErrTestFailed},
+		{"A.16 add array value", `{"foo":["bar"]}`,
+			`[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`, nil},
+
+		// Массивы
+		{"add at array end by index", `{"foo":["a"]}`,
+			`[{"op":"add","path":"/foo/1","value":"b"}]`, `{"foo":["a","b"]}`, nil},
+		{"add out of range", `{"foo":["a"]}`,
+			`[{"op":"add","path":"/foo/2","value":"b"}]`, "", ErrInvalidIndex},
+		{"remove out of range", `{"foo":["a"]}`,
+			`[{"op":"remove","path":"/foo/1"}]`, "", ErrInvalidIndex},
+		{"replace past end", `{"foo":["a"]}`,
+			`[{"op":"replace","path":"/foo/-","value":"b"}]`, "", ErrInvalidIndex},
+		{"negative index", `{"foo":["a"]}`,
+			`[{"op":"remove","path":"/foo/-1"}]`, "", ErrInvalidIndex},
+		{"leading zero index", `{"foo":["a","b"]}`,
+			`[{"op":"remove","path":"/foo/01"}]`, "", ErrInvalidIndex},
+
+		// Экранирование и сопоставление ключей
+		{"~1 escape in path", `{"a/b":1}`,
+			`[{"op":"replace","path":"/a~1b","value":2}]`, `{"a/b":2}`, nil},
+		{"~0 escape in path", `{"m~n":1}`,
+			`[{"op":"remove","path":"/m~0n"}]`, `{}`, nil},
+		{"case-insensitive key", `{"Style":"Lager","Name":"A"}`,
+			`[{"op":"replace","path":"/style","value":"IPA"},{"op":"test","path":"/NAME","value":"A"}]`,
+			`{"Style":"IPA","Name":"A"}`, nil},
+		{"exact key wins over case-insensitive", `{"Style":"a","style":"b"}`,
+			`[{"op":"replace","path":"/style","value":"c"}]`, `{"Style":"a","style":"c"}`, nil},
+
+		// Остальные операции и ошибки
+		{"copy value", `{"foo":{"bar":[1]}}`,
+			`[{"op":"copy","from":"/foo","path":"/baz"},{"op":"add","path":"/baz/bar/-","value":2}]`,
+			`{"foo":{"bar":[1]},"baz":{"bar":[1,2]}}`, nil},
+		{"move into own child", `{"foo":{"bar":1}}`,
+			`[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`, "", nil},
+		{"replace missing member", `{"foo":1}`,
+			`[{"op":"replace","path":"/bar","value":2}]`, "", ErrPathNotFound},
+		{"remove missing member", `{"foo":1}`,
+			`[{"op":"remove","path":"/bar"}]`, "", ErrPathNotFound},
+		{"add null value", `{"foo":1}`,
+			`[{"op":"add","path":"/bar","value":null}]`, `{"foo":1,"bar":null}`, nil},
+		{"missing value", `{"foo":1}`,
+			`[{"op":"add","path":"/bar"}]`, "", nil},
+		{"invalid pointer", `{"foo":1}`,
+			`[{"op":"remove","path":"foo"}]`, "", ErrInvalidPointer},
+		{"unknown operation", `{"foo":1}`,
+			`[{"op":"increment","path":"/foo"}]`, "", ErrUnknownOp},
+		{"replace whole document", `{"foo":1}`,
+			`[{"op":"replace","path":"","value":{"bar":2}}]`, `{"bar":2}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JSONPatch([]byte(tt.doc), []byte(tt.patch))
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("expected error, got %s", got)
+				}
+				if tt.err != nil && !errors.Is(err, tt.err) {
+					t.Fatalf("expected %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+// TestJSONPatchAtomic проверяет, что ошибка в любой операции отменяет весь патч.
+func TestJSONPatchAtomic(t *testing.T) {
+	doc := []byte(`{"Version":3,"Name":"A","Tags":["x"]}`)
+	original := string(doc)
+	patch := []byte(`[
+		{"op":"replace","path":"/Name","value":"B"},
+		{"op":"add","path":"/Tags/-","value":"y"},
+		{"op":"test","path":"/Version","value":2}
+	]`)
+
+	got, err := JSONPatch(doc, patch)
+	if !errors.Is(err, ErrTestFailed) {
+		t.Fatalf("expected ErrTestFailed, got %v", err)
+	}
+	if got != nil {
+		t.Fatalf("expected no document on error, got %s", got)
+	}
+	if string(doc) != original {
+		t.Fatalf("source document modified: %s", doc)
+	}
+}
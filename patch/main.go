@@ -0,0 +1,112 @@
+// Package patch реализует частичное обновление JSON-документов по RFC 7396 (JSON Merge Patch)
+// и RFC 6902 (JSON Patch).
+//
+// Ключи объектов сопоставляются так же, как в encoding/json: сначала точное совпадение,
+// затем совпадение без учёта регистра. Это позволяет клиенту передавать {"style": "IPA"}
+// для поля Style.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Медиатипы тел запросов, поддерживаемые пакетом.
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// MergePatch применяет JSON Merge Patch (RFC 7396) к документу doc и возвращает результат.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(target, p))
+}
+
+// merge рекурсивно накладывает patch на target: null удаляет ключ,
+// объекты сливаются, остальные значения заменяются целиком.
+func merge(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any)
+	}
+
+	for key, val := range p {
+		key = resolveKey(t, key)
+		if val == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = merge(t[key], val)
+	}
+	return t
+}
+
+// decode разбирает JSON с сохранением чисел в виде json.Number.
+func decode(data []byte) (any, error) {
+	var v any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// resolveKey возвращает имя существующего ключа объекта, совпадающего с key
+// точно или без учёта регистра. Если такого нет, возвращает key.
+func resolveKey(obj map[string]any, key string) string {
+	if _, ok := obj[key]; ok {
+		return key
+	}
+	for existing := range obj {
+		if strings.EqualFold(existing, key) {
+			return existing
+		}
+	}
+	return key
+}
+
+// Mentions сообщает, затрагивает ли патч body типа mediaType ключ верхнего уровня key:
+// для JSON Merge Patch — есть ли ключ в объекте патча, для JSON Patch — есть ли операция
+// с путём или источником "/key". Ключи сравниваются так же, как при применении патча.
+func Mentions(mediaType string, body []byte, key string) bool {
+	switch mediaType {
+	case MergePatchType:
+		p, err := decode(body)
+		if err != nil {
+			return false
+		}
+		obj, ok := p.(map[string]any)
+		if !ok {
+			return false
+		}
+		_, ok = obj[resolveKey(obj, key)]
+		return ok
+	case JSONPatchType:
+		var ops []Operation
+		if err := json.Unmarshal(body, &ops); err != nil {
+			return false
+		}
+		for _, op := range ops {
+			for _, pointer := range []string{op.Path, op.From} {
+				if path, err := parsePointer(pointer); err == nil && len(path) > 0 && strings.EqualFold(path[0], key) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package patch
+
+import "testing"
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		// Примеры из приложения A RFC 7396
+		{"replace member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"null deletes member", `{"a":"b"}`, `{"a":null}`, `{}`},
+		{"null deletes one of members", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"array replaced", `{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"value replaced by array", `{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{"nested merge", `{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{"arrays are not merged", `{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{"non-object patch replaces document", `["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{"object patch replaces array", `{"a":"b"}`, `["c"]`, `["c"]`},
+		{"null patch", `{"a":"foo"}`, `null`, `null`},
+		{"string patch", `{"a":"foo"}`, `"bar"`, `"bar"`},
+		{"null inside new object is dropped", `{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{"patch creates nested object", `[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{"deep null", `{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+
+		// Сопоставление ключей без учёта регистра
+		{"case-insensitive key", `{"Style":"Lager","Name":"A"}`, `{"style":"IPA"}`, `{"Style":"IPA","Name":"A"}`},
+		{"case-insensitive null", `{"Style":"Lager","Name":"A"}`, `{"STYLE":null}`, `{"Name":"A"}`},
+		{"case-insensitive nested", `{"Meta":{"Tags":["a"],"Note":"x"}}`, `{"meta":{"note":"y"}}`, `{"Meta":{"Tags":["a"],"Note":"y"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestMergePatchInvalid(t *testing.T) {
+	if _, err := MergePatch([]byte(`{"a":1}`), []byte(`{"a":`)); err == nil {
+		t.Fatal("expected error for invalid patch")
+	}
+	if _, err := MergePatch([]byte(`{`), []byte(`{"a":1}`)); err == nil {
+		t.Fatal("expected error for invalid document")
+	}
+}
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType string
+		body      string
+		want      bool
+	}{
+		{"merge patch with key", MergePatchType, `{"Version":2,"Name":"A"}`, true},
+		{"merge patch case-insensitive", MergePatchType, `{"version":2}`, true},
+		{"merge patch null", MergePatchType, `{"Version":null}`, true},
+		{"merge patch without key", MergePatchType, `{"Name":"A"}`, false},
+		{"merge patch nested key", MergePatchType, `{"Meta":{"Version":2}}`, false},
+		{"json patch test", JSONPatchType, `[{"op":"test","path":"/Version","value":1}]`, true},
+		{"json patch move from", JSONPatchType, `[{"op":"move","from":"/version","path":"/Old"}]`, true},
+		{"json patch other path", JSONPatchType, `[{"op":"replace","path":"/Name","value":"A"}]`, false},
+		{"json patch nested path", JSONPatchType, `[{"op":"replace","path":"/Meta/Version","value":1}]`, false},
+		{"invalid body", JSONPatchType, `{`, false},
+		{"unsupported type", "application/json", `{"Version":2}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Mentions(tt.mediaType, []byte(tt.body), "Version"); got != tt.want {
+				t.Errorf("Mentions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
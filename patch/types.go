@@ -0,0 +1,24 @@
+// Package patch реализует частичное обновление JSON-документов по RFC 7396 и RFC 6902.
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Operation описывает одну операцию JSON Patch (RFC 6902).
+type Operation struct {
+	Op    string          `json:"op"`              // Тип операции: add, remove, replace, move, copy, test
+	Path  string          `json:"path"`            // JSON Pointer на изменяемое значение
+	From  string          `json:"from,omitempty"`  // Источник для move и copy
+	Value json.RawMessage `json:"value,omitempty"` // Значение для add, replace и test
+}
+
+// Ошибки применения JSON Patch.
+var (
+	ErrPathNotFound   = errors.New("path not found")
+	ErrInvalidPointer = errors.New("invalid JSON pointer")
+	ErrInvalidIndex   = errors.New("invalid array index")
+	ErrTestFailed     = errors.New("test operation failed")
+	ErrUnknownOp      = errors.New("unknown operation")
+)
@@ -0,0 +1,120 @@
+// Package ratelimit реализует ограничение частоты запросов по алгоритму token bucket.
+package ratelimit
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"server/auth"
+	"server/request"
+	"server/router"
+	"server/types"
+	"strconv"
+	"time"
+)
+
+// Limiter ограничивает частоту запросов каждого клиента. Клиент определяется
+// по ключу API или пользователю, а для анонимных запросов — по IP-адресу
+// с учётом доверенных прокси.
+type Limiter struct {
+	store Store
+	limit Limit
+	byIP  bool // Учитывать запросы только по IP-адресу (см. NewIP)
+}
+
+// New создаёт ограничитель с лимитом по умолчанию limit.
+// Лимит с Requests <= 0 отключает ограничение.
+func New(store Store, limit Limit) *Limiter {
+	return &Limiter{store: store, limit: limit}
+}
+
+// NewIP создаёт ограничитель, учитывающий запросы по IP-адресу клиента независимо
+// от аутентификации и собственных лимитов маршрутов. Подключается до аутентификации,
+// чтобы запросы с неверными токенами и подписями тоже расходовали лимит и перебор
+// ключей не оставался без ограничений.
+func NewIP(store Store, limit Limit) *Limiter {
+	return &Limiter{store: store, limit: limit, byIP: true}
+}
+
+// Middleware возвращает middleware, списывающий запрос из лимита клиента и
+// добавляющий заголовки RateLimit-* (см. writeHeaders). При превышении лимита
+// запрос отклоняется с кодом 429 и заголовком Retry-After. Маршруты с собственным
+// лимитом (см. RouteKey) учитываются отдельно от общего лимита.
+func (l *Limiter) Middleware() types.Middleware {
+	return func(next types.HandlerFunc) types.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			limit, scope := l.limit, "*"
+			if l.byIP {
+				scope = "ip"
+			} else if h := router.CurrentHandle(r); h != nil {
+				if override, ok := h.Value(RouteKey).(Limit); ok {
+					limit, scope = override, h.Method()+" "+h.Pattern()
+				}
+			}
+			if limit.Requests <= 0 || limit.Window <= 0 {
+				next(w, r)
+				return
+			}
+
+			key := clientKey(r)
+			if l.byIP {
+				key = ipKey(r)
+			}
+			result, err := l.store.Take(r.Context(), scope+"|"+key, limit)
+			if err != nil {
+				log.Printf("ratelimit: store failed, request allowed: %v", err)
+				next(w, r)
+				return
+			}
+
+			writeHeaders(w.Header(), limit, result)
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				req := request.InitRequest(r)
+				router.WriteJson(w, r, types.JsonResponse{Status: "error", Message: req.T("rate_limit.exceeded"), Code: http.StatusTooManyRequests})
+				return
+			}
+
+			next(w, r)
+		}
+	}
+}
+
+// writeHeaders добавляет заголовки RateLimit-* с результатом result лимита limit.
+// Если заголовки уже выставил другой ограничитель (например, лимит по IP-адресу),
+// остаются сведения о более строгом из двух лимитов: с меньшим остатком запросов,
+// а при равном остатке — с более поздним восстановлением.
+func writeHeaders(header http.Header, limit Limit, result Result) {
+	reset := ceilSeconds(result.Reset)
+	if remaining, err := strconv.Atoi(header.Get("RateLimit-Remaining")); err == nil {
+		if remaining < result.Remaining {
+			return
+		}
+		if current, err := strconv.Atoi(header.Get("RateLimit-Reset")); err == nil && remaining == result.Remaining && current >= reset {
+			return
+		}
+	}
+	header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, ceilSeconds(limit.Window)))
+	header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(reset))
+}
+
+// clientKey возвращает ключ клиента: аутентифицированного субъекта или IP-адрес.
+func clientKey(r *http.Request) string {
+	if p := auth.PrincipalFromContext(r.Context()); p != nil {
+		return fmt.Sprintf("%s:%d", p.Kind, p.ID)
+	}
+	return ipKey(r)
+}
+
+// ipKey возвращает ключ клиента по его IP-адресу.
+func ipKey(r *http.Request) string {
+	return "ip:" + request.InitRequest(r).ClientIP()
+}
+
+// ceilSeconds округляет длительность вверх до целых секунд.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
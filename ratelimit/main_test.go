@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"server/auth"
+	"server/request"
+	"server/router"
+	"server/types"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testAuth сохраняет в контексте пользователя с идентификатором из заголовка X-User.
+func testAuth(next types.HandlerFunc) types.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if id, err := strconv.Atoi(r.Header.Get("X-User")); err == nil {
+			r = r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{Kind: "user", ID: uint(id)}))
+		}
+		next(w, r)
+	}
+}
+
+// newTestRouter создаёт маршрутизатор с ограничителем по IP-адресу ipLimit до аутентификации
+// и ограничителем клиентов limit после неё, как в server/routes.go.
+// Маршрут /random/ имеет собственный лимит в один запрос.
+func newTestRouter(t *testing.T, ipLimit, limit Limit) *router.Router {
+	t.Helper()
+	store := NewMemoryStore()
+	r, err := router.InitRouter(func(r *router.Router) {
+		r.Use(NewIP(store, ipLimit).Middleware(), testAuth, New(store, limit).Middleware())
+		ok := func(*request.Request, map[string]string) types.JsonResponse {
+			return types.JsonResponse{Status: "success"}
+		}
+		r.Get("/beers/", ok)
+		r.Get("/random/", ok).Set(RouteKey, Limit{Requests: 1, Window: time.Minute})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// serve выполняет запрос GET path от адреса remote с заголовками headers вида "Имя", "значение".
+func serve(r http.Handler, path, remote string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remote
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// expectStatus проверяет HTTP-код ответа.
+func expectStatus(t *testing.T, w *httptest.ResponseRecorder, code int) {
+	t.Helper()
+	if w.Code != code {
+		t.Fatalf("expected status %d, got %d: %s", code, w.Code, w.Body)
+	}
+}
+
+func TestMiddlewareAnonymousByIP(t *testing.T) {
+	r := newTestRouter(t, Limit{Requests: 100, Window: time.Minute}, Limit{Requests: 1, Window: time.Minute})
+
+	expectStatus(t, serve(r, "/beers/", "203.0.113.1:1000"), http.StatusOK)
+	w := serve(r, "/beers/", "203.0.113.1:2000")
+	expectStatus(t, w, http.StatusTooManyRequests)
+	if w.Header().Get("Retry-After") != "60" {
+		t.Fatalf("expected Retry-After 60, got %q", w.Header().Get("Retry-After"))
+	}
+	expectStatus(t, serve(r, "/beers/", "203.0.113.2:1000"), http.StatusOK)
+}
+
+func TestMiddlewarePrincipalKey(t *testing.T) {
+	r := newTestRouter(t, Limit{Requests: 100, Window: time.Minute}, Limit{Requests: 1, Window: time.Minute})
+
+	expectStatus(t, serve(r, "/beers/", "203.0.113.1:1000", "X-User", "1"), http.StatusOK)
+	// Тот же пользователь с другого адреса расходует тот же лимит
+	expectStatus(t, serve(r, "/beers/", "203.0.113.2:1000", "X-User", "1"), http.StatusTooManyRequests)
+	// Другой пользователь и анонимный запрос с того же адреса учитываются отдельно
+	expectStatus(t, serve(r, "/beers/", "203.0.113.1:1000", "X-User", "2"), http.StatusOK)
+	expectStatus(t, serve(r, "/beers/", "203.0.113.1:1000"), http.StatusOK)
+}
+
+func TestMiddlewareRouteScope(t *testing.T) {
+	r := newTestRouter(t, Limit{Requests: 100, Window: time.Minute}, Limit{Requests: 2, Window: time.Minute})
+
+	expectStatus(t, serve(r, "/random/", "203.0.113.1:1000"), http.StatusOK)
+	expectStatus(t, serve(r, "/random/", "203.0.113.1:1000"), http.StatusTooManyRequests)
+	// Собственный лимит маршрута не расходует общий лимит клиента
+	expectStatus(t, serve(r, "/beers/", "203.0.113.1:1000"), http.StatusOK)
+	expectStatus(t, serve(r, "/beers/", "203.0.113.1:1000"), http.StatusOK)
+}
+
+func TestMiddlewareIPLimit(t *testing.T) {
+	r := newTestRouter(t, Limit{Requests: 2, Window: time.Minute}, Limit{Requests: 100, Window: time.Minute})
+
+	// Лимит по IP-адресу действует независимо от аутентификации
+	expectStatus(t, serve(r, "/beers/", "203.0.113.1:1000", "X-User", "1"), http.StatusOK)
+	expectStatus(t, serve(r, "/beers/", "203.0.113.1:1000", "X-User", "2"), http.StatusOK)
+	expectStatus(t, serve(r, "/beers/", "203.0.113.1:1000", "X-User", "3"), http.StatusTooManyRequests)
+}
+
+func TestMiddlewareHeaders(t *testing.T) {
+	tests := []struct {
+		name      string
+		ipLimit   Limit
+		limit     Limit
+		policy    string
+		remaining string
+	}{
+		{"client limit is stricter", Limit{Requests: 10, Window: time.Minute}, Limit{Requests: 3, Window: time.Minute}, "3;w=60", "2"},
+		{"ip limit is stricter", Limit{Requests: 3, Window: time.Minute}, Limit{Requests: 10, Window: time.Minute}, "3;w=60", "2"},
+		{"equal remaining, slower reset wins", Limit{Requests: 3, Window: time.Minute}, Limit{Requests: 3, Window: time.Hour}, "3;w=3600", "2"},
+		{"ip limit disabled", Limit{}, Limit{Requests: 5, Window: time.Second}, "5;w=1", "4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(newTestRouter(t, tt.ipLimit, tt.limit), "/beers/", "203.0.113.1:1000")
+			expectStatus(t, w, http.StatusOK)
+			if got := w.Header().Get("RateLimit-Policy"); got != tt.policy {
+				t.Errorf("RateLimit-Policy = %q, want %q", got, tt.policy)
+			}
+			if got := w.Header().Get("RateLimit-Remaining"); got != tt.remaining {
+				t.Errorf("RateLimit-Remaining = %q, want %q", got, tt.remaining)
+			}
+			if got := w.Header().Values("RateLimit-Limit"); len(got) != 1 {
+				t.Errorf("expected a single RateLimit-Limit header, got %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Package ratelimit реализует ограничение частоты запросов по алгоритму token bucket.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval — как часто из памяти удаляются полностью восстановившиеся корзины.
+const sweepInterval = time.Minute
+
+// MemoryStore хранит корзины лимитов в памяти процесса.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time // Источник текущего времени, подменяется в тестах
+}
+
+// bucket — состояние одной корзины токенов.
+type bucket struct {
+	tokens  float64       // доступные токены
+	updated time.Time     // время последнего пересчёта
+	window  time.Duration // время полного восстановления
+}
+
+// NewMemoryStore создаёт хранилище лимитов в памяти.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), lastSweep: time.Now(), now: time.Now}
+}
+
+// Take списывает токен из корзины key, предварительно восстановив токены за прошедшее время.
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := s.now()
+	capacity := float64(limit.Requests)
+	rate := capacity / limit.Window.Seconds() // токенов в секунду
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+	b.window = limit.Window
+
+	result := Result{Limit: limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((capacity - b.tokens) / rate)
+	return result, nil
+}
+
+// sweep удаляет корзины, которые к моменту now полностью восстановились бы,
+// — они неотличимы от новых.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) >= b.window {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+// seconds переводит дробное число секунд в time.Duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
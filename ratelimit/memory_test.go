@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// newTestStore создаёт хранилище в памяти с управляемыми часами.
+func newTestStore() (*MemoryStore, *time.Time) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.lastSweep = now
+	store.now = func() time.Time { return now }
+	return store, &now
+}
+
+// take списывает запрос из корзины key и завершает тест при ошибке хранилища.
+func take(t *testing.T, store Store, key string, limit Limit) Result {
+	t.Helper()
+	result, err := store.Take(context.Background(), key, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestMemoryStoreBurst(t *testing.T) {
+	store, _ := newTestStore()
+	limit := Limit{Requests: 3, Window: 3 * time.Second}
+
+	for i := 2; i >= 0; i-- {
+		result := take(t, store, "a", limit)
+		if !result.Allowed || result.Remaining != i || result.Limit != 3 {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+	}
+	result := take(t, store, "a", limit)
+	if result.Allowed || result.Remaining != 0 {
+		t.Fatalf("expected request to be rejected: %+v", result)
+	}
+	if result.RetryAfter != time.Second || result.Reset != 3*time.Second {
+		t.Fatalf("unexpected retry after %v and reset %v", result.RetryAfter, result.Reset)
+	}
+}
+
+func TestMemoryStoreRefill(t *testing.T) {
+	store, now := newTestStore()
+	limit := Limit{Requests: 2, Window: 2 * time.Second}
+
+	take(t, store, "a", limit)
+	take(t, store, "a", limit)
+	if take(t, store, "a", limit).Allowed {
+		t.Fatal("expected empty bucket")
+	}
+
+	// Токены восстанавливаются равномерно: один за секунду
+	*now = now.Add(500 * time.Millisecond)
+	if result := take(t, store, "a", limit); result.Allowed || result.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("expected rejection with half a token, got %+v", result)
+	}
+	*now = now.Add(500 * time.Millisecond)
+	if !take(t, store, "a", limit).Allowed {
+		t.Fatal("expected a refilled token")
+	}
+
+	// Корзина не переполняется сверх Requests
+	*now = now.Add(time.Hour)
+	if result := take(t, store, "a", limit); result.Remaining != 1 {
+		t.Fatalf("expected bucket capped at capacity, got %+v", result)
+	}
+}
+
+func TestMemoryStoreKeys(t *testing.T) {
+	store, _ := newTestStore()
+	limit := Limit{Requests: 1, Window: time.Minute}
+
+	take(t, store, "a", limit)
+	if take(t, store, "a", limit).Allowed {
+		t.Fatal("expected bucket a to be empty")
+	}
+	if !take(t, store, "b", limit).Allowed {
+		t.Fatal("bucket b must be independent of bucket a")
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	store, now := newTestStore()
+	take(t, store, "a", Limit{Requests: 1, Window: time.Second})
+	take(t, store, "b", Limit{Requests: 1, Window: time.Hour})
+
+	*now = now.Add(sweepInterval)
+	take(t, store, "c", Limit{Requests: 1, Window: time.Second})
+	if _, ok := store.buckets["a"]; ok {
+		t.Fatal("recovered bucket a should be swept")
+	}
+	if _, ok := store.buckets["b"]; !ok {
+		t.Fatal("bucket b is still recovering and must be kept")
+	}
+}
@@ -0,0 +1,38 @@
+// Package ratelimit реализует ограничение частоты запросов по алгоритму token bucket.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// routeKey — тип ключа настройки обработчика для переопределения лимита.
+type routeKey struct{}
+
+// RouteKey — ключ, под которым в router.Handle.Set задаётся собственный лимит маршрута:
+//
+//	r.Get("/beer/random/", handler).Set(ratelimit.RouteKey, ratelimit.Limit{Requests: 10, Window: time.Minute})
+var RouteKey = routeKey{}
+
+// Limit задаёт лимит: не больше Requests запросов за Window.
+// Запросы восстанавливаются равномерно, так что допускаются всплески до Requests подряд.
+type Limit struct {
+	Requests int           // Размер корзины — максимальное число запросов подряд
+	Window   time.Duration // Время полного восстановления корзины
+}
+
+// Result описывает результат списания запроса из лимита.
+type Result struct {
+	Allowed    bool          // Запрос разрешён
+	Limit      int           // Размер лимита
+	Remaining  int           // Сколько запросов ещё доступно
+	Reset      time.Duration // Через сколько лимит полностью восстановится
+	RetryAfter time.Duration // Через сколько можно повторить отклонённый запрос
+}
+
+// Store хранит состояние лимитов. Реализация в памяти подходит для одного экземпляра
+// сервера; для нескольких экземпляров нужна реализация поверх общего хранилища.
+type Store interface {
+	// Take списывает один запрос из лимита limit для ключа key.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
@@ -0,0 +1,26 @@
+// Package rbac реализует ролевую модель доступа.
+package rbac
+
+import (
+	"server/models"
+
+	"gorm.io/gorm"
+)
+
+// DefaultRoles — роли, создаваемые при запуске, и их разрешения.
+var DefaultRoles = map[string][]string{
+	"reader": {"beer:read", "snack:read"},
+	"editor": {"beer:read", "beer:create", "beer:update", "snack:read", "snack:create", "snack:update"},
+	"admin":  {"*"},
+}
+
+// EnsureDefaultRoles создаёт роли DefaultRoles и их разрешения, если их ещё нет.
+// Разрешения, выданные ролям вручную, сохраняются.
+func EnsureDefaultRoles(db *gorm.DB) error {
+	for name, permissions := range DefaultRoles {
+		if _, err := models.EnsureRole(db, name, permissions...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Package rbac реализует ролевую модель доступа: разрешения клиентов определяются
+// их ролями, хранящимися в базе данных.
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"server/auth"
+	"server/models"
+	"server/request"
+	"server/router"
+	"server/types"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Authorizer проверяет разрешения аутентифицированных клиентов.
+// Разрешения клиента кешируются на время ttl, поэтому изменения ролей
+// вступают в силу с задержкой не больше ttl.
+type Authorizer struct {
+	db    *gorm.DB
+	ttl   time.Duration
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// cacheEntry — закешированный набор разрешений клиента.
+type cacheEntry struct {
+	permissions []string
+	expiresAt   time.Time
+}
+
+// NewAuthorizer создаёт проверку разрешений по ролям из базы данных db.
+func NewAuthorizer(db *gorm.DB, ttl time.Duration) *Authorizer {
+	return &Authorizer{db: db, ttl: ttl, cache: make(map[string]cacheEntry)}
+}
+
+// Require возвращает middleware, который пропускает запрос, только если у клиента
+// есть все перечисленные разрешения. Анонимные запросы отклоняются с кодом 401,
+// запросы без нужных разрешений — с кодом 403.
+// Подходит для передачи в router.Router.Guard.
+func (a *Authorizer) Require(permissions ...string) types.Middleware {
+	return func(next types.HandlerFunc) types.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			req := request.InitRequest(r)
+			p := auth.PrincipalFromContext(r.Context())
+			if p == nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				router.WriteJson(w, r, types.JsonResponse{Status: "error", Message: req.T("auth.required"), Code: http.StatusUnauthorized})
+				return
+			}
+
+			granted, err := a.Permissions(r.Context(), p)
+			if err != nil {
+				log.Printf("rbac: failed to load permissions of %s %d: %v", p.Kind, p.ID, err)
+				router.WriteJson(w, r, types.JsonResponse{Status: "error", Message: req.T("auth.failed"), Code: http.StatusInternalServerError})
+				return
+			}
+			for _, permission := range permissions {
+				if !Allows(granted, permission) {
+					router.WriteJson(w, r, types.JsonResponse{Status: "error", Message: req.T("auth.forbidden", permission), Code: http.StatusForbidden})
+					return
+				}
+			}
+
+			next(w, r)
+		}
+	}
+}
+
+// Permissions возвращает разрешения клиента p по всем его ролям.
+func (a *Authorizer) Permissions(ctx context.Context, p *auth.Principal) ([]string, error) {
+	key := fmt.Sprintf("%s:%d", p.Kind, p.ID)
+
+	a.mu.Lock()
+	entry, ok := a.cache[key]
+	a.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.permissions, nil
+	}
+
+	var permissions []string
+	var err error
+	db := a.db.WithContext(ctx)
+	switch p.Kind {
+	case "user":
+		permissions, err = models.GetUserPermissions(db, p.ID)
+	case "api_key":
+		permissions, err = models.GetAPIKeyPermissions(db, p.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	a.cache[key] = cacheEntry{permissions: permissions, expiresAt: time.Now().Add(a.ttl)}
+	a.mu.Unlock()
+	return permissions, nil
+}
+
+// Allows проверяет, покрывает ли набор granted разрешение permission.
+// Разрешение "*" покрывает всё, "beer:*" — все разрешения вида "beer:...".
+func Allows(granted []string, permission string) bool {
+	for _, g := range granted {
+		if g == "*" || g == permission {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(g, "*"); ok && strings.HasPrefix(permission, prefix) {
+			return true
+		}
+	}
+	return false
+}
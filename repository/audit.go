@@ -0,0 +1,27 @@
+// Package repository содержит интерфейсы доступа к данным и их реализации
+// поверх GORM и в памяти.
+package repository
+
+import (
+	"context"
+	"server/database"
+	"server/models"
+
+	"gorm.io/gorm"
+)
+
+// GormAudit читает журнал изменений из базы данных через GORM.
+// Чтение может выполняться на реплике.
+type GormAudit struct {
+	db *gorm.DB
+}
+
+// NewGormAudit создаёт хранилище журнала изменений поверх подключения db.
+func NewGormAudit(db *gorm.DB) *GormAudit {
+	return &GormAudit{db: db}
+}
+
+// List возвращает записи журнала по фильтру filter, начиная с самых новых.
+func (r *GormAudit) List(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	return models.GetAuditEntries(database.ConnOr(ctx, r.db), filter)
+}
@@ -0,0 +1,157 @@
+// Package repository содержит интерфейсы доступа к данным и их реализации
+// поверх GORM и в памяти.
+package repository
+
+import (
+	"context"
+	"errors"
+	"server/audit"
+	"server/database"
+	"server/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormBeers хранит пиво в базе данных через GORM. Каждое изменение
+// записывается в журнал аудита в той же транзакции. Если контекст содержит
+// транзакцию запроса (см. database.Transaction), хранилище работает в ней.
+// Чтение может выполняться на реплике, запись — всегда на основной базе.
+type GormBeers struct {
+	db *gorm.DB
+}
+
+// NewGormBeers создаёт хранилище пива поверх подключения db.
+func NewGormBeers(db *gorm.DB) *GormBeers {
+	return &GormBeers{db: db}
+}
+
+// Random возвращает случайное пиво.
+func (r *GormBeers) Random(ctx context.Context) (*models.Beer, error) {
+	var beer models.Beer
+	if err := database.ConnOr(ctx, r.db).Order(database.RandomOrder()).First(&beer).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &beer, nil
+}
+
+// List возвращает все записи пива, при непустом query — найденные полнотекстовым поиском.
+func (r *GormBeers) List(ctx context.Context, query string) ([]models.Beer, error) {
+	db := database.ConnOr(ctx, r.db)
+	if query != "" {
+		db = database.Search(db, query, models.BeerSearchColumns...)
+	}
+	return models.GetAllBeers(db)
+}
+
+// Find возвращает пиво по идентификатору.
+func (r *GormBeers) Find(ctx context.Context, id uint) (*models.Beer, error) {
+	beer, err := models.GetBeerByID(database.ConnOr(ctx, r.db), id)
+	return beer, notFound(err)
+}
+
+// Create сохраняет новую запись пива.
+func (r *GormBeers) Create(ctx context.Context, beer *models.Beer) error {
+	return database.ConnOr(database.UsePrimary(ctx), r.db).Transaction(func(tx *gorm.DB) error {
+		if err := models.CreateBeer(tx, beer); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Create, "beer", beer.ID, nil, beer)
+	})
+}
+
+// Update сохраняет изменения записи пива, если её версия не изменилась.
+func (r *GormBeers) Update(ctx context.Context, beer *models.Beer) error {
+	return database.ConnOr(database.UsePrimary(ctx), r.db).Transaction(func(tx *gorm.DB) error {
+		// Блокирующее чтение видит последнюю зафиксированную версию записи
+		before, err := models.GetBeerByID(tx.Clauses(clause.Locking{Strength: "UPDATE"}), beer.ID)
+		if err != nil {
+			return notFound(err)
+		}
+		if before.Version != beer.Version {
+			return &ConflictError{Current: before}
+		}
+		if err := models.UpdateBeer(tx, beer); err != nil {
+			if errors.Is(err, models.ErrVersionConflict) {
+				return &ConflictError{Current: before}
+			}
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Update, "beer", beer.ID, before, beer)
+	})
+}
+
+// Delete мягко удаляет запись пива.
+func (r *GormBeers) Delete(ctx context.Context, id uint) error {
+	return database.ConnOr(database.UsePrimary(ctx), r.db).Transaction(func(tx *gorm.DB) error {
+		before, err := models.GetBeerByID(tx, id)
+		if err != nil {
+			return notFound(err)
+		}
+		if err := models.DeleteBeer(tx, id); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Delete, "beer", id, before, nil)
+	})
+}
+
+// Trash возвращает мягко удалённые записи пива.
+func (r *GormBeers) Trash(ctx context.Context) ([]models.Beer, error) {
+	return models.GetDeletedBeers(database.ConnOr(ctx, r.db))
+}
+
+// Restore восстанавливает запись пива из корзины.
+func (r *GormBeers) Restore(ctx context.Context, id uint) (*models.Beer, error) {
+	var restored *models.Beer
+	err := database.ConnOr(database.UsePrimary(ctx), r.db).Transaction(func(tx *gorm.DB) error {
+		before, err := models.GetDeletedBeerByID(tx, id)
+		if err != nil {
+			return notFound(err)
+		}
+		if err := models.RestoreBeer(tx, id); err != nil {
+			return notFound(err)
+		}
+		if restored, err = models.GetBeerByID(tx, id); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Restore, "beer", id, before, restored)
+	})
+	return restored, err
+}
+
+// ForceDelete окончательно удаляет запись пива.
+func (r *GormBeers) ForceDelete(ctx context.Context, id uint) error {
+	return database.ConnOr(database.UsePrimary(ctx), r.db).Transaction(func(tx *gorm.DB) error {
+		var before models.Beer
+		if err := tx.Unscoped().First(&before, id).Error; err != nil {
+			return notFound(err)
+		}
+		if err := models.ForceDeleteBeer(tx, id); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Purge, "beer", id, before, nil)
+	})
+}
+
+// Purge окончательно удаляет записи пива, мягко удалённые раньше before.
+// Для каждой удалённой записи в журнал изменений добавляется запись audit.Purge.
+func (r *GormBeers) Purge(ctx context.Context, before time.Time) (int64, error) {
+	var purged []models.Beer
+	err := database.ConnOr(database.UsePrimary(ctx), r.db).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if purged, err = models.PurgeBeers(tx, before); err != nil {
+			return err
+		}
+		for i := range purged {
+			if err := audit.Record(ctx, tx, audit.Purge, "beer", purged[i].ID, purged[i], nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(purged)), nil
+}
@@ -0,0 +1,196 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"server/audit"
+	"server/database/dbtest"
+	"server/migrate"
+	"server/migrations"
+	"server/models"
+	"server/repository"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// openDB открывает тестовую базу SQLite в памяти и применяет к ней миграции приложения.
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := dbtest.Open(t)
+	if _, err := migrate.New(db, migrations.All()).Up(0); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// expectActions проверяет действия в журнале изменений сущности entity с идентификатором id,
+// начиная с самых новых.
+func expectActions(t *testing.T, db *gorm.DB, entity string, id uint, want ...string) {
+	t.Helper()
+	entries, err := repository.NewGormAudit(db).List(context.Background(), models.AuditFilter{Entity: entity, EntityID: id, Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, len(entries))
+	for i := range entries {
+		got[i] = entries[i].Action
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected audit actions %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected audit actions %v, got %v", want, got)
+		}
+	}
+}
+
+func TestGormBeersCreateUpdate(t *testing.T) {
+	db := openDB(t)
+	beers := repository.NewGormBeers(db)
+	ctx := context.Background()
+
+	beer := &models.Beer{Name: "Porter", Style: "Porter"}
+	if err := beers.Create(ctx, beer); err != nil {
+		t.Fatal(err)
+	}
+	if beer.ID == 0 || beer.Version != 1 {
+		t.Fatalf("unexpected created beer: %+v", beer)
+	}
+
+	found, err := beers.Find(ctx, beer.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found.Name = "Baltic Porter"
+	if err := beers.Update(ctx, found); err != nil {
+		t.Fatal(err)
+	}
+	if found.Version != 2 {
+		t.Fatalf("expected version 2 after update, got %d", found.Version)
+	}
+
+	// Изменение по устаревшей версии отклоняется и возвращает текущее состояние
+	beer.Name = "Stout"
+	err = beers.Update(ctx, beer)
+	var conflict *repository.ConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("expected ConflictError, got %v", err)
+	}
+	if current, ok := conflict.Current.(*models.Beer); !ok || current.Name != "Baltic Porter" || current.Version != 2 {
+		t.Fatalf("unexpected current state: %+v", conflict.Current)
+	}
+
+	if _, err := beers.Find(ctx, beer.ID+100); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	expectActions(t, db, "beer", beer.ID, audit.Update, audit.Create)
+}
+
+func TestGormBeersTrash(t *testing.T) {
+	db := openDB(t)
+	beers := repository.NewGormBeers(db)
+	ctx := context.Background()
+
+	kept := &models.Beer{Name: "Lager"}
+	deleted := &models.Beer{Name: "Pilsner"}
+	for _, beer := range []*models.Beer{kept, deleted} {
+		if err := beers.Create(ctx, beer); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := beers.Delete(ctx, deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := beers.Find(ctx, deleted.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("deleted beer must not be found, got %v", err)
+	}
+	trash, err := beers.Trash(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 1 || trash[0].ID != deleted.ID {
+		t.Fatalf("unexpected trash: %+v", trash)
+	}
+
+	restored, err := beers.Restore(ctx, deleted.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Version != 2 || restored.DeletedAt.Valid {
+		t.Fatalf("unexpected restored beer: %+v", restored)
+	}
+	if _, err := beers.Restore(ctx, kept.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("restoring a live beer must fail with ErrNotFound, got %v", err)
+	}
+
+	if err := beers.ForceDelete(ctx, kept.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := beers.ForceDelete(ctx, kept.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	expectActions(t, db, "beer", deleted.ID, audit.Restore, audit.Delete, audit.Create)
+	expectActions(t, db, "beer", kept.ID, audit.Purge, audit.Create)
+}
+
+func TestGormBeersPurge(t *testing.T) {
+	db := openDB(t)
+	beers := repository.NewGormBeers(db)
+	ctx := context.Background()
+
+	old := &models.Beer{Name: "Old"}
+	recent := &models.Beer{Name: "Recent"}
+	for _, beer := range []*models.Beer{old, recent} {
+		if err := beers.Create(ctx, beer); err != nil {
+			t.Fatal(err)
+		}
+		if err := beers.Delete(ctx, beer.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Unscoped().Model(&models.Beer{}).Where("id = ?", old.ID).Update("deleted_at", time.Now().Add(-48*time.Hour))
+
+	purged, err := beers.Purge(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 1 {
+		t.Fatalf("expected 1 purged beer, got %d", purged)
+	}
+	trash, _ := beers.Trash(ctx)
+	if len(trash) != 1 || trash[0].ID != recent.ID {
+		t.Fatalf("unexpected trash after purge: %+v", trash)
+	}
+	expectActions(t, db, "beer", old.ID, audit.Purge, audit.Delete, audit.Create)
+}
+
+func TestGormSnacksSearch(t *testing.T) {
+	db := openDB(t)
+	snacks := repository.NewGormSnacks(db)
+	ctx := context.Background()
+
+	for _, name := range []string{"Salted peanuts", "Dried squid", "Smoked cheese"} {
+		if err := snacks.Create(ctx, &models.Snack{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, err := snacks.List(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 snacks, got %d", len(all))
+	}
+	found, err := snacks.List(ctx, "squid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Name != "Dried squid" {
+		t.Fatalf("unexpected search result: %+v", found)
+	}
+}
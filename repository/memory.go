@@ -0,0 +1,404 @@
+// Package repository содержит интерфейсы доступа к данным и их реализации
+// поверх GORM и в памяти.
+package repository
+
+import (
+	"context"
+	"math/rand/v2"
+	"server/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryBeers хранит пиво в памяти процесса. Предназначен для тестов
+// и не пишет журнал аудита.
+type MemoryBeers struct {
+	mu     sync.RWMutex
+	nextID uint
+	rows   map[uint]models.Beer
+}
+
+// NewMemoryBeers создаёт пустое хранилище пива в памяти.
+func NewMemoryBeers() *MemoryBeers {
+	return &MemoryBeers{rows: make(map[uint]models.Beer)}
+}
+
+// Random возвращает случайное пиво.
+func (r *MemoryBeers) Random(ctx context.Context) (*models.Beer, error) {
+	beers, _ := r.List(ctx, "")
+	if len(beers) == 0 {
+		return nil, ErrNotFound
+	}
+	return &beers[rand.IntN(len(beers))], nil
+}
+
+// List возвращает все записи пива, при непустом query — содержащие его
+// в названии, пивоварне, стиле или описании без учёта регистра.
+func (r *MemoryBeers) List(ctx context.Context, query string) ([]models.Beer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	beers := make([]models.Beer, 0, len(r.rows))
+	for _, beer := range r.rows {
+		if beer.DeletedAt.Valid {
+			continue
+		}
+		if query != "" && !contains(query, beer.Name, beer.Brewery, beer.Style, beer.Description) {
+			continue
+		}
+		beers = append(beers, beer)
+	}
+	sort.Slice(beers, func(i, j int) bool { return beers[i].ID < beers[j].ID })
+	return beers, nil
+}
+
+// Find возвращает пиво по идентификатору.
+func (r *MemoryBeers) Find(ctx context.Context, id uint) (*models.Beer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	beer, ok := r.rows[id]
+	if !ok || beer.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	return &beer, nil
+}
+
+// Create сохраняет новую запись пива и присваивает ей идентификатор.
+func (r *MemoryBeers) Create(ctx context.Context, beer *models.Beer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	now := time.Now()
+	beer.ID = r.nextID
+	beer.Version = 1
+	beer.CreatedAt, beer.UpdatedAt = now, now
+	r.rows[beer.ID] = *beer
+	return nil
+}
+
+// Update сохраняет изменения записи пива, если её версия не изменилась.
+func (r *MemoryBeers) Update(ctx context.Context, beer *models.Beer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.rows[beer.ID]
+	if !ok || current.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	if current.Version != beer.Version {
+		return &ConflictError{Current: &current}
+	}
+	beer.Version++
+	beer.CreatedAt = current.CreatedAt
+	beer.UpdatedAt = time.Now()
+	r.rows[beer.ID] = *beer
+	return nil
+}
+
+// Delete мягко удаляет запись пива.
+func (r *MemoryBeers) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	beer, ok := r.rows[id]
+	if !ok || beer.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	beer.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.rows[id] = beer
+	return nil
+}
+
+// Trash возвращает мягко удалённые записи пива, начиная с последних удалённых.
+func (r *MemoryBeers) Trash(ctx context.Context) ([]models.Beer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	beers := make([]models.Beer, 0)
+	for _, beer := range r.rows {
+		if beer.DeletedAt.Valid {
+			beers = append(beers, beer)
+		}
+	}
+	sort.Slice(beers, func(i, j int) bool { return beers[i].DeletedAt.Time.After(beers[j].DeletedAt.Time) })
+	return beers, nil
+}
+
+// Restore восстанавливает запись пива из корзины.
+func (r *MemoryBeers) Restore(ctx context.Context, id uint) (*models.Beer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	beer, ok := r.rows[id]
+	if !ok || !beer.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	beer.DeletedAt = gorm.DeletedAt{}
+	beer.Version++
+	beer.UpdatedAt = time.Now()
+	r.rows[id] = beer
+	return &beer, nil
+}
+
+// ForceDelete окончательно удаляет запись пива.
+func (r *MemoryBeers) ForceDelete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rows[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.rows, id)
+	return nil
+}
+
+// Purge окончательно удаляет записи пива, мягко удалённые раньше before.
+func (r *MemoryBeers) Purge(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, beer := range r.rows {
+		if beer.DeletedAt.Valid && beer.DeletedAt.Time.Before(before) {
+			delete(r.rows, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// MemorySnacks хранит закуски в памяти процесса. Предназначен для тестов
+// и не пишет журнал аудита.
+type MemorySnacks struct {
+	mu     sync.RWMutex
+	nextID uint
+	rows   map[uint]models.Snack
+}
+
+// NewMemorySnacks создаёт пустое хранилище закусок в памяти.
+func NewMemorySnacks() *MemorySnacks {
+	return &MemorySnacks{rows: make(map[uint]models.Snack)}
+}
+
+// Random возвращает случайную закуску.
+func (r *MemorySnacks) Random(ctx context.Context) (*models.Snack, error) {
+	snacks, _ := r.List(ctx, "")
+	if len(snacks) == 0 {
+		return nil, ErrNotFound
+	}
+	return &snacks[rand.IntN(len(snacks))], nil
+}
+
+// List возвращает все записи закусок, при непустом query — содержащие его
+// в названии, типе, описании или стране без учёта регистра.
+func (r *MemorySnacks) List(ctx context.Context, query string) ([]models.Snack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snacks := make([]models.Snack, 0, len(r.rows))
+	for _, snack := range r.rows {
+		if snack.DeletedAt.Valid {
+			continue
+		}
+		if query != "" && !contains(query, snack.Name, snack.Type, snack.Description, snack.Country) {
+			continue
+		}
+		snacks = append(snacks, snack)
+	}
+	sort.Slice(snacks, func(i, j int) bool { return snacks[i].ID < snacks[j].ID })
+	return snacks, nil
+}
+
+// Find возвращает закуску по идентификатору.
+func (r *MemorySnacks) Find(ctx context.Context, id uint) (*models.Snack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snack, ok := r.rows[id]
+	if !ok || snack.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	return &snack, nil
+}
+
+// Create сохраняет новую запись закуски и присваивает ей идентификатор.
+func (r *MemorySnacks) Create(ctx context.Context, snack *models.Snack) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	now := time.Now()
+	snack.ID = r.nextID
+	snack.Version = 1
+	snack.CreatedAt, snack.UpdatedAt = now, now
+	r.rows[snack.ID] = *snack
+	return nil
+}
+
+// Update сохраняет изменения записи закуски, если её версия не изменилась.
+func (r *MemorySnacks) Update(ctx context.Context, snack *models.Snack) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.rows[snack.ID]
+	if !ok || current.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	if current.Version != snack.Version {
+		return &ConflictError{Current: &current}
+	}
+	snack.Version++
+	snack.CreatedAt = current.CreatedAt
+	snack.UpdatedAt = time.Now()
+	r.rows[snack.ID] = *snack
+	return nil
+}
+
+// Delete мягко удаляет запись закуски.
+func (r *MemorySnacks) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snack, ok := r.rows[id]
+	if !ok || snack.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	snack.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.rows[id] = snack
+	return nil
+}
+
+// Trash возвращает мягко удалённые записи закусок, начиная с последних удалённых.
+func (r *MemorySnacks) Trash(ctx context.Context) ([]models.Snack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snacks := make([]models.Snack, 0)
+	for _, snack := range r.rows {
+		if snack.DeletedAt.Valid {
+			snacks = append(snacks, snack)
+		}
+	}
+	sort.Slice(snacks, func(i, j int) bool { return snacks[i].DeletedAt.Time.After(snacks[j].DeletedAt.Time) })
+	return snacks, nil
+}
+
+// Restore восстанавливает запись закуски из корзины.
+func (r *MemorySnacks) Restore(ctx context.Context, id uint) (*models.Snack, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snack, ok := r.rows[id]
+	if !ok || !snack.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	snack.DeletedAt = gorm.DeletedAt{}
+	snack.Version++
+	snack.UpdatedAt = time.Now()
+	r.rows[id] = snack
+	return &snack, nil
+}
+
+// ForceDelete окончательно удаляет запись закуски.
+func (r *MemorySnacks) ForceDelete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rows[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.rows, id)
+	return nil
+}
+
+// Purge окончательно удаляет записи закусок, мягко удалённые раньше before.
+func (r *MemorySnacks) Purge(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, snack := range r.rows {
+		if snack.DeletedAt.Valid && snack.DeletedAt.Time.Before(before) {
+			delete(r.rows, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// MemoryAudit хранит журнал изменений в памяти процесса. Предназначен для тестов:
+// хранилища в памяти журнал не пишут, записи добавляются методом Add.
+type MemoryAudit struct {
+	mu      sync.RWMutex
+	entries []models.AuditEntry
+}
+
+// NewMemoryAudit создаёт пустой журнал изменений в памяти.
+func NewMemoryAudit() *MemoryAudit {
+	return &MemoryAudit{}
+}
+
+// Add добавляет записи в журнал и присваивает им идентификаторы.
+// Нулевое время создания заменяется текущим.
+func (r *MemoryAudit) Add(entries ...models.AuditEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range entries {
+		entry.ID = uint(len(r.entries) + 1)
+		if entry.CreatedAt.IsZero() {
+			entry.CreatedAt = time.Now()
+		}
+		r.entries = append(r.entries, entry)
+	}
+}
+
+// List возвращает записи журнала по фильтру filter, начиная с самых новых.
+func (r *MemoryAudit) List(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]models.AuditEntry, 0)
+	skipped := 0
+	for i := len(r.entries) - 1; i >= 0 && len(entries) < filter.Limit; i-- {
+		entry := r.entries[i]
+		if !matchAudit(entry, filter) {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// matchAudit сообщает, удовлетворяет ли запись entry условиям фильтра filter.
+func matchAudit(entry models.AuditEntry, filter models.AuditFilter) bool {
+	return (filter.Entity == "" || entry.Entity == filter.Entity) &&
+		(filter.EntityID == 0 || entry.EntityID == filter.EntityID) &&
+		(filter.Action == "" || entry.Action == filter.Action) &&
+		(filter.ActorKind == "" || entry.ActorKind == filter.ActorKind) &&
+		(filter.ActorID == 0 || entry.ActorID == filter.ActorID) &&
+		(filter.RequestID == "" || entry.RequestID == filter.RequestID) &&
+		(filter.From.IsZero() || !entry.CreatedAt.Before(filter.From)) &&
+		(filter.To.IsZero() || entry.CreatedAt.Before(filter.To))
+}
+
+// contains сообщает, входит ли query хотя бы в одно из значений без учёта регистра.
+func contains(query string, values ...string) bool {
+	query = strings.ToLower(query)
+	for _, value := range values {
+		if strings.Contains(strings.ToLower(value), query) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+// Package repository содержит интерфейсы доступа к данным и их реализации
+// поверх GORM и в памяти.
+package repository
+
+import (
+	"context"
+	"errors"
+	"server/audit"
+	"server/database"
+	"server/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormSnacks хранит закуски в базе данных через GORM. Каждое изменение
+// записывается в журнал аудита в той же транзакции. Если контекст содержит
+// транзакцию запроса (см. database.Transaction), хранилище работает в ней.
+// Чтение может выполняться на реплике, запись — всегда на основной базе.
+type GormSnacks struct {
+	db *gorm.DB
+}
+
+// NewGormSnacks создаёт хранилище закусок поверх подключения db.
+func NewGormSnacks(db *gorm.DB) *GormSnacks {
+	return &GormSnacks{db: db}
+}
+
+// Random возвращает случайную закуску.
+func (r *GormSnacks) Random(ctx context.Context) (*models.Snack, error) {
+	var snack models.Snack
+	if err := database.ConnOr(ctx, r.db).Order(database.RandomOrder()).First(&snack).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &snack, nil
+}
+
+// List возвращает все записи закусок, при непустом query — найденные полнотекстовым поиском.
+func (r *GormSnacks) List(ctx context.Context, query string) ([]models.Snack, error) {
+	db := database.ConnOr(ctx, r.db)
+	if query != "" {
+		db = database.Search(db, query, models.SnackSearchColumns...)
+	}
+	return models.GetAllSnacks(db)
+}
+
+// Find возвращает закуску по идентификатору.
+func (r *GormSnacks) Find(ctx context.Context, id uint) (*models.Snack, error) {
+	snack, err := models.GetSnackByID(database.ConnOr(ctx, r.db), id)
+	return snack, notFound(err)
+}
+
+// Create сохраняет новую запись закуски.
+func (r *GormSnacks) Create(ctx context.Context, snack *models.Snack) error {
+	return database.ConnOr(database.UsePrimary(ctx), r.db).Transaction(func(tx *gorm.DB) error {
+		if err := models.CreateSnack(tx, snack); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Create, "snack", snack.ID, nil, snack)
+	})
+}
+
+// Update сохраняет изменения записи закуски, если её версия не изменилась.
+func (r *GormSnacks) Update(ctx context.Context, snack *models.Snack) error {
+	return database.ConnOr(database.UsePrimary(ctx), r.db).Transaction(func(tx *gorm.DB) error {
+		// Блокирующее чтение видит последнюю зафиксированную версию записи
+		before, err := models.GetSnackByID(tx.Clauses(clause.Locking{Strength: "UPDATE"}), snack.ID)
+		if err != nil {
+			return notFound(err)
+		}
+		if before.Version != snack.Version {
+			return &ConflictError{Current: before}
+		}
+		if err := models.UpdateSnack(tx, snack); err != nil {
+			if errors.Is(err, models.ErrVersionConflict) {
+				return &ConflictError{Current: before}
+			}
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Update, "snack", snack.ID, before, snack)
+	})
+}
+
+// Delete мягко удаляет запись закуски.
+func (r *GormSnacks) Delete(ctx context.Context, id uint) error {
+	return database.ConnOr(database.UsePrimary(ctx), r.db).Transaction(func(tx *gorm.DB) error {
+		before, err := models.GetSnackByID(tx, id)
+		if err != nil {
+			return notFound(err)
+		}
+		if err := models.DeleteSnack(tx, id); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Delete, "snack", id, before, nil)
+	})
+}
+
+// Trash возвращает мягко удалённые записи закуски.
+func (r *GormSnacks) Trash(ctx context.Context) ([]models.Snack, error) {
+	return models.GetDeletedSnacks(database.ConnOr(ctx, r.db))
+}
+
+// Restore восстанавливает запись закуски из корзины.
+func (r *GormSnacks) Restore(ctx context.Context, id uint) (*models.Snack, error) {
+	var restored *models.Snack
+	err := database.ConnOr(database.UsePrimary(ctx), r.db).Transaction(func(tx *gorm.DB) error {
+		before, err := models.GetDeletedSnackByID(tx, id)
+		if err != nil {
+			return notFound(err)
+		}
+		if err := models.RestoreSnack(tx, id); err != nil {
+			return notFound(err)
+		}
+		if restored, err = models.GetSnackByID(tx, id); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Restore, "snack", id, before, restored)
+	})
+	return restored, err
+}
+
+// ForceDelete окончательно удаляет запись закуски.
+func (r *GormSnacks) ForceDelete(ctx context.Context, id uint) error {
+	return database.ConnOr(database.UsePrimary(ctx), r.db).Transaction(func(tx *gorm.DB) error {
+		var before models.Snack
+		if err := tx.Unscoped().First(&before, id).Error; err != nil {
+			return notFound(err)
+		}
+		if err := models.ForceDeleteSnack(tx, id); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Purge, "snack", id, before, nil)
+	})
+}
+
+// Purge окончательно удаляет записи закуски, мягко удалённые раньше before.
+// Для каждой удалённой записи в журнал изменений добавляется запись audit.Purge.
+func (r *GormSnacks) Purge(ctx context.Context, before time.Time) (int64, error) {
+	var purged []models.Snack
+	err := database.ConnOr(database.UsePrimary(ctx), r.db).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if purged, err = models.PurgeSnacks(tx, before); err != nil {
+			return err
+		}
+		for i := range purged {
+			if err := audit.Record(ctx, tx, audit.Purge, "snack", purged[i].ID, purged[i], nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(purged)), nil
+}
@@ -0,0 +1,99 @@
+// Package repository содержит интерфейсы доступа к данным и их реализации
+// поверх GORM и в памяти.
+package repository
+
+import (
+	"context"
+	"errors"
+	"server/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound возвращается, если запись не найдена.
+var ErrNotFound = errors.New("record not found")
+
+// ErrConflict возвращается Update, если версия записи не совпадает с ожидаемой:
+// после чтения клиентом её изменил другой запрос.
+var ErrConflict = errors.New("record was modified concurrently")
+
+// ConflictError — ошибка ErrConflict вместе с текущим состоянием записи.
+type ConflictError struct {
+	Current any // Текущее состояние записи в хранилище
+}
+
+// Error возвращает текст ErrConflict.
+func (e *ConflictError) Error() string {
+	return ErrConflict.Error()
+}
+
+// Unwrap позволяет сравнивать ошибку с ErrConflict через errors.Is.
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// BeerRepository хранит записи пива.
+type BeerRepository interface {
+	// Random возвращает случайное пиво.
+	Random(ctx context.Context) (*models.Beer, error)
+	// List возвращает все записи; непустой query включает полнотекстовый поиск.
+	List(ctx context.Context, query string) ([]models.Beer, error)
+	// Find возвращает пиво по идентификатору.
+	Find(ctx context.Context, id uint) (*models.Beer, error)
+	// Create сохраняет новую запись и заполняет её идентификатор.
+	Create(ctx context.Context, beer *models.Beer) error
+	// Update сохраняет изменения существующей записи, если её версия совпадает с beer.Version,
+	// и увеличивает версию; иначе возвращает *ConflictError.
+	Update(ctx context.Context, beer *models.Beer) error
+	// Delete удаляет запись по идентификатору (мягкое удаление).
+	Delete(ctx context.Context, id uint) error
+	// Trash возвращает мягко удалённые записи, начиная с последних удалённых.
+	Trash(ctx context.Context) ([]models.Beer, error)
+	// Restore восстанавливает запись из корзины и возвращает её.
+	Restore(ctx context.Context, id uint) (*models.Beer, error)
+	// ForceDelete окончательно удаляет запись, в том числе находящуюся в корзине.
+	ForceDelete(ctx context.Context, id uint) error
+	// Purge окончательно удаляет записи, мягко удалённые раньше before, и возвращает их число.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SnackRepository хранит записи закусок.
+type SnackRepository interface {
+	// Random возвращает случайную закуску.
+	Random(ctx context.Context) (*models.Snack, error)
+	// List возвращает все записи; непустой query включает полнотекстовый поиск.
+	List(ctx context.Context, query string) ([]models.Snack, error)
+	// Find возвращает закуску по идентификатору.
+	Find(ctx context.Context, id uint) (*models.Snack, error)
+	// Create сохраняет новую запись и заполняет её идентификатор.
+	Create(ctx context.Context, snack *models.Snack) error
+	// Update сохраняет изменения существующей записи, если её версия совпадает с snack.Version,
+	// и увеличивает версию; иначе возвращает *ConflictError.
+	Update(ctx context.Context, snack *models.Snack) error
+	// Delete удаляет запись по идентификатору (мягкое удаление).
+	Delete(ctx context.Context, id uint) error
+	// Trash возвращает мягко удалённые записи, начиная с последних удалённых.
+	Trash(ctx context.Context) ([]models.Snack, error)
+	// Restore восстанавливает запись из корзины и возвращает её.
+	Restore(ctx context.Context, id uint) (*models.Snack, error)
+	// ForceDelete окончательно удаляет запись, в том числе находящуюся в корзине.
+	ForceDelete(ctx context.Context, id uint) error
+	// Purge окончательно удаляет записи, мягко удалённые раньше before, и возвращает их число.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// AuditRepository читает журнал изменений. Записи добавляются функцией audit.Record
+// в транзакциях хранилищ сущностей.
+type AuditRepository interface {
+	// List возвращает записи журнала по фильтру filter, начиная с самых новых.
+	List(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+}
+
+// notFound приводит ошибку отсутствия записи GORM к ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,31 @@
+// Package request предоставляет удобный обёртку для работы с HTTP-запросами.
+package request
+
+import "context"
+
+// contextKey используется для хранения значений в контексте HTTP-запроса.
+type contextKey string
+
+const idKey contextKey = "request_id"
+
+// WithID возвращает копию контекста с идентификатором запроса id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey, id)
+}
+
+// IDFromContext возвращает идентификатор запроса из контекста,
+// или пустую строку, если он не задан.
+func IDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(idKey).(string)
+	return id
+}
+
+// Context возвращает контекст HTTP-запроса.
+func (r *Request) Context() context.Context {
+	return r.Req.Context()
+}
+
+// ID возвращает идентификатор запроса, присвоенный middleware RequestID.
+func (r *Request) ID() string {
+	return IDFromContext(r.Req.Context())
+}
@@ -0,0 +1,60 @@
+// Package request предоставляет удобный обёртку для работы с HTTP-запросами.
+package request
+
+import (
+	"net/http"
+	"time"
+)
+
+// Cookie возвращает значение cookie с именем name,
+// или defaultValue, если cookie отсутствует.
+func (r *Request) Cookie(name string, defaultValue string) string {
+	c, err := r.Req.Cookie(name)
+	if err != nil {
+		return defaultValue
+	}
+	return c.Value
+}
+
+// HasCookie проверяет наличие cookie с указанным именем.
+func (r *Request) HasCookie(name string) bool {
+	_, err := r.Req.Cookie(name)
+	return err == nil
+}
+
+// NewCookie создаёт cookie с безопасными настройками по умолчанию:
+// HttpOnly, SameSite=Lax, Path="/" и Secure для запросов по HTTPS.
+// Нулевой maxAge создаёт сессионную cookie.
+func (r *Request) NewCookie(name, value string, maxAge time.Duration) *http.Cookie {
+	c := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	}
+	if maxAge > 0 {
+		c.MaxAge = int(maxAge.Seconds())
+		c.Expires = time.Now().Add(maxAge)
+	}
+	return c
+}
+
+// SetCookie добавляет cookie в ответ. Cookie отправляются вместе с JSON-ответом обработчика.
+func (r *Request) SetCookie(c *http.Cookie) {
+	r.cookies = append(r.cookies, c)
+}
+
+// ForgetCookie добавляет в ответ cookie с истёкшим сроком действия, удаляя её у клиента.
+func (r *Request) ForgetCookie(name string) {
+	c := r.NewCookie(name, "", 0)
+	c.MaxAge = -1
+	c.Expires = time.Unix(0, 0)
+	r.SetCookie(c)
+}
+
+// ResponseCookies возвращает cookie, добавленные в ответ через SetCookie.
+func (r *Request) ResponseCookies() []*http.Cookie {
+	return r.cookies
+}
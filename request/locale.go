@@ -0,0 +1,30 @@
+// Package request предоставляет удобный обёртку для работы с HTTP-запросами.
+package request
+
+import (
+	"errors"
+	"server/i18n"
+)
+
+// Locale возвращает язык клиента, выбранный по заголовку Accept-Language.
+func (r *Request) Locale() string {
+	if r.locale == "" {
+		r.locale = i18n.Negotiate(r.Req.Header.Get("Accept-Language"))
+	}
+	return r.locale
+}
+
+// T возвращает сообщение key, переведённое на язык клиента.
+func (r *Request) T(key string, args ...any) string {
+	return i18n.Translate(r.Locale(), key, args...)
+}
+
+// Localize возвращает описание ошибки на языке клиента,
+// если ошибка поддерживает перевод, и err.Error() в противном случае.
+func (r *Request) Localize(err error) string {
+	var l i18n.Localizable
+	if errors.As(err, &l) {
+		return l.Localize(r.T)
+	}
+	return err.Error()
+}
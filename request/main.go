@@ -41,13 +41,10 @@ func (r *Request) Url() string {
 	return r.Req.URL.RequestURI()
 }
 
-// FullUrl возвращает полный URL запроса с протоколом и хостом.
+// FullUrl возвращает полный URL запроса с протоколом и хостом
+// с учётом заголовков доверенных прокси.
 func (r *Request) FullUrl() string {
-	scheme := "http"
-	if r.Req.TLS != nil {
-		scheme = "https"
-	}
-	return scheme + "://" + r.Req.Host + r.Req.URL.RequestURI()
+	return r.Scheme() + "://" + r.Host() + r.Req.URL.RequestURI()
 }
 
 // Method возвращает HTTP-метод запроса.
@@ -0,0 +1,65 @@
+// Package request предоставляет удобный обёртку для работы с HTTP-запросами.
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"reflect"
+	"server/patch"
+)
+
+// ErrUnsupportedPatchType возвращается Patch, если Content-Type запроса не указывает тип патча.
+var ErrUnsupportedPatchType = errors.New("unsupported patch media type")
+
+// Patch применяет тело запроса как частичное обновление к структуре v.
+// Тип обновления выбирается по заголовку Content-Type:
+// application/merge-patch+json — RFC 7396, application/json-patch+json — RFC 6902.
+// Для остальных типов, в том числе application/json и отсутствующего заголовка,
+// возвращается ErrUnsupportedPatchType.
+func (r *Request) Patch(v any) error {
+	defer r.Req.Body.Close()
+	body, err := io.ReadAll(r.Req.Body)
+	if err != nil {
+		return err
+	}
+
+	doc, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Req.Header.Get("Content-Type"))
+	switch mediaType {
+	case patch.MergePatchType:
+		doc, err = patch.MergePatch(doc, body)
+	case patch.JSONPatchType:
+		doc, err = patch.JSONPatch(doc, body)
+	default:
+		return fmt.Errorf("%w %q", ErrUnsupportedPatchType, mediaType)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Декодируем в новое значение, чтобы удалённые патчем поля получили нулевые значения.
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("patch target must be a non-nil pointer")
+	}
+	fresh := reflect.New(target.Elem().Type())
+	if err := json.Unmarshal(doc, fresh.Interface()); err != nil {
+		return err
+	}
+	target.Elem().Set(fresh.Elem())
+	r.patchType, r.patchBody = mediaType, body
+	return nil
+}
+
+// PatchMentions сообщает, затрагивает ли применённый через Patch патч поле key,
+// например задаёт или проверяет операцией test версию записи.
+func (r *Request) PatchMentions(key string) bool {
+	return patch.Mentions(r.patchType, r.patchBody, key)
+}
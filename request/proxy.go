@@ -0,0 +1,231 @@
+// Package request предоставляет удобный обёртку для работы с HTTP-запросами.
+package request
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var (
+	proxiesMu      sync.RWMutex
+	trustedProxies []*net.IPNet
+)
+
+// SetTrustedProxies задаёт список доверенных прокси в виде CIDR (например "10.0.0.0/8")
+// или отдельных IP-адресов. Заголовки Forwarded и X-Forwarded-* учитываются
+// только для запросов, пришедших с этих адресов.
+func SetTrustedProxies(cidrs []string) error {
+	nets, err := ParseCIDRs(cidrs)
+	if err != nil {
+		return err
+	}
+
+	proxiesMu.Lock()
+	trustedProxies = nets
+	proxiesMu.Unlock()
+	return nil
+}
+
+// ParseCIDRs разбирает список сетей в виде CIDR или отдельных IP-адресов.
+func ParseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", cidr)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// IsTrustedProxy проверяет, входит ли IP-адрес в список доверенных прокси.
+func IsTrustedProxy(ip net.IP) bool {
+	proxiesMu.RLock()
+	defer proxiesMu.RUnlock()
+	for _, ipNet := range trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP возвращает IP-адрес клиента. Если запрос пришёл от доверенного прокси,
+// цепочка Forwarded/X-Forwarded-For просматривается справа налево до первого
+// недоверенного адреса.
+func (r *Request) ClientIP() string {
+	remote := remoteIP(r.Req)
+	if remote == nil {
+		return r.Req.RemoteAddr
+	}
+	if !IsTrustedProxy(remote) {
+		return remote.String()
+	}
+
+	chain := forwardedFor(r.Req)
+	for i := len(chain) - 1; i >= 0; i-- {
+		ip := parseNodeIP(chain[i])
+		if ip == nil {
+			break
+		}
+		if !IsTrustedProxy(ip) || i == 0 {
+			return ip.String()
+		}
+	}
+	return remote.String()
+}
+
+// Scheme возвращает протокол запроса ("http" или "https") с учётом
+// заголовков доверенных прокси (см. forwardedParam и lastValue).
+func (r *Request) Scheme() string {
+	if r.fromTrustedProxy() {
+		proto := forwardedParam(r.Req, "proto")
+		if proto == "" {
+			proto = lastValue(r.Req.Header.Values("X-Forwarded-Proto"))
+		}
+		if proto = strings.ToLower(proto); proto == "http" || proto == "https" {
+			return proto
+		}
+	}
+
+	if r.Req.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// Host возвращает хост запроса с учётом заголовков доверенных прокси
+// (см. forwardedParam и lastValue).
+func (r *Request) Host() string {
+	if r.fromTrustedProxy() {
+		host := forwardedParam(r.Req, "host")
+		if host == "" {
+			host = lastValue(r.Req.Header.Values("X-Forwarded-Host"))
+		}
+		if host != "" {
+			return host
+		}
+	}
+	return r.Req.Host
+}
+
+// fromTrustedProxy проверяет, что непосредственный отправитель запроса — доверенный прокси.
+func (r *Request) fromTrustedProxy() bool {
+	remote := remoteIP(r.Req)
+	return remote != nil && IsTrustedProxy(remote)
+}
+
+// remoteIP извлекает IP-адрес непосредственного отправителя из RemoteAddr.
+func remoteIP(req *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+// forwardedFor возвращает цепочку адресов клиента и прокси в порядке следования:
+// из параметров for заголовка Forwarded, а при его отсутствии — из X-Forwarded-For.
+func forwardedFor(req *http.Request) []string {
+	var chain []string
+	for _, element := range forwardedElements(req) {
+		if node, ok := element["for"]; ok {
+			chain = append(chain, node)
+		}
+	}
+	if len(chain) > 0 {
+		return chain
+	}
+
+	for _, header := range req.Header.Values("X-Forwarded-For") {
+		for _, node := range strings.Split(header, ",") {
+			if node = strings.TrimSpace(node); node != "" {
+				chain = append(chain, node)
+			}
+		}
+	}
+	return chain
+}
+
+// forwardedParam возвращает значение параметра key заголовка Forwarded, добавленное
+// доверенным прокси. Элементы просматриваются справа налево, как в ClientIP: последний
+// элемент добавлен непосредственным отправителем, а каждый предыдущий — узлом из
+// параметра for следующего, пока этот узел — доверенный прокси. Из элементов доверенных
+// прокси берётся самый левый, в котором есть key: он добавлен прокси, принявшим запрос
+// от клиента. Значения, которые мог подставить сам клиент, не учитываются.
+func forwardedParam(req *http.Request, key string) string {
+	elements := forwardedElements(req)
+	val := ""
+	for i := len(elements) - 1; i >= 0; i-- {
+		if v, ok := elements[i][key]; ok {
+			val = v
+		}
+		ip := parseNodeIP(elements[i]["for"])
+		if ip == nil || !IsTrustedProxy(ip) {
+			break
+		}
+	}
+	return val
+}
+
+// forwardedElements разбирает заголовок Forwarded (RFC 7239) на список элементов,
+// каждый из которых — набор пар параметр=значение.
+func forwardedElements(req *http.Request) []map[string]string {
+	var elements []map[string]string
+	for _, header := range req.Header.Values("Forwarded") {
+		for _, element := range strings.Split(header, ",") {
+			pairs := make(map[string]string)
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok {
+					continue
+				}
+				pairs[strings.ToLower(key)] = strings.Trim(val, `"`)
+			}
+			if len(pairs) > 0 {
+				elements = append(elements, pairs)
+			}
+		}
+	}
+	return elements
+}
+
+// parseNodeIP разбирает адрес узла вида "1.2.3.4", "1.2.3.4:80" или "[::1]:80".
+// Возвращает nil для обфусцированных и неизвестных узлов.
+func parseNodeIP(node string) net.IP {
+	node = strings.TrimSpace(node)
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		node = host
+	}
+	return net.ParseIP(strings.Trim(node, "[]"))
+}
+
+// lastValue возвращает последнее значение заголовка X-Forwarded-*, разделённого запятыми
+// или переданного несколькими строками, — добавленное непосредственным отправителем,
+// доверенным прокси. Значения левее мог подставить клиент.
+func lastValue(headers []string) string {
+	if len(headers) == 0 {
+		return ""
+	}
+	header := headers[len(headers)-1]
+	if i := strings.LastIndex(header, ","); i >= 0 {
+		header = header[i+1:]
+	}
+	return strings.TrimSpace(header)
+}
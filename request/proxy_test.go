@@ -0,0 +1,113 @@
+package request
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"testing"
+)
+
+// newProxiedRequest создаёт запрос от адреса remote с заголовками headers вида "Имя", "значение".
+func newProxiedRequest(remote string, headers ...string) *Request {
+	req := httptest.NewRequest("GET", "http://backend.internal/", nil)
+	req.RemoteAddr = remote
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Add(headers[i], headers[i+1])
+	}
+	return InitRequest(req)
+}
+
+// trustProxies задаёт доверенные прокси на время теста.
+func trustProxies(t *testing.T, cidrs ...string) {
+	t.Helper()
+	if err := SetTrustedProxies(cidrs); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetTrustedProxies(nil) })
+}
+
+func TestClientIP(t *testing.T) {
+	trustProxies(t, "10.0.0.0/8")
+
+	tests := []struct {
+		name    string
+		remote  string
+		headers []string
+		want    string
+	}{
+		{"direct", "203.0.113.7:1234", nil, "203.0.113.7"},
+		{"untrusted sender ignores headers", "203.0.113.7:1234", []string{"X-Forwarded-For", "1.1.1.1"}, "203.0.113.7"},
+		{"trusted proxy", "10.0.0.1:1234", []string{"X-Forwarded-For", "198.51.100.4"}, "198.51.100.4"},
+		{"spoofed leftmost entry", "10.0.0.1:1234", []string{"X-Forwarded-For", "1.1.1.1, 198.51.100.4"}, "198.51.100.4"},
+		{"trusted hops skipped", "10.0.0.1:1234", []string{"X-Forwarded-For", "1.1.1.1, 198.51.100.4, 10.0.0.2"}, "198.51.100.4"},
+		{"multiple header lines", "10.0.0.1:1234", []string{"X-Forwarded-For", "1.1.1.1", "X-Forwarded-For", "198.51.100.4"}, "198.51.100.4"},
+		{"forwarded", "10.0.0.1:1234", []string{"Forwarded", `for=1.1.1.1, for="[2001:db8::1]:443";proto=https`}, "2001:db8::1"},
+		{"obfuscated node", "10.0.0.1:1234", []string{"Forwarded", "for=_hidden"}, "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newProxiedRequest(tt.remote, tt.headers...).ClientIP(); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheme(t *testing.T) {
+	trustProxies(t, "10.0.0.0/8")
+
+	tests := []struct {
+		name    string
+		remote  string
+		headers []string
+		want    string
+	}{
+		{"direct", "203.0.113.7:1234", nil, "http"},
+		{"untrusted sender ignores headers", "203.0.113.7:1234", []string{"X-Forwarded-Proto", "https"}, "http"},
+		{"trusted proxy", "10.0.0.1:1234", []string{"X-Forwarded-Proto", "https"}, "https"},
+		{"spoofed leftmost value", "10.0.0.1:1234", []string{"X-Forwarded-Proto", "https, http"}, "http"},
+		{"spoofed header line", "10.0.0.1:1234", []string{"X-Forwarded-Proto", "https", "X-Forwarded-Proto", "http"}, "http"},
+		{"forwarded", "10.0.0.1:1234", []string{"Forwarded", "for=198.51.100.4;proto=https"}, "https"},
+		{"forwarded spoofed element", "10.0.0.1:1234", []string{"Forwarded", "for=1.1.1.1;proto=https, for=198.51.100.4;proto=http"}, "http"},
+		{"forwarded outermost trusted proxy", "10.0.0.1:1234", []string{"Forwarded", "for=198.51.100.4;proto=https, for=10.0.0.2;proto=http"}, "https"},
+		{"unknown protocol", "10.0.0.1:1234", []string{"X-Forwarded-Proto", "gopher"}, "http"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newProxiedRequest(tt.remote, tt.headers...).Scheme(); got != tt.want {
+				t.Errorf("Scheme() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	r := newProxiedRequest("203.0.113.7:1234")
+	r.Req.TLS = &tls.ConnectionState{}
+	if got := r.Scheme(); got != "https" {
+		t.Errorf("Scheme() over TLS = %q, want https", got)
+	}
+}
+
+func TestHost(t *testing.T) {
+	trustProxies(t, "10.0.0.0/8")
+
+	tests := []struct {
+		name    string
+		remote  string
+		headers []string
+		want    string
+	}{
+		{"direct", "203.0.113.7:1234", nil, "backend.internal"},
+		{"untrusted sender ignores headers", "203.0.113.7:1234", []string{"X-Forwarded-Host", "evil.example"}, "backend.internal"},
+		{"trusted proxy", "10.0.0.1:1234", []string{"X-Forwarded-Host", "api.example.com"}, "api.example.com"},
+		{"spoofed leftmost value", "10.0.0.1:1234", []string{"X-Forwarded-Host", "evil.example, api.example.com"}, "api.example.com"},
+		{"forwarded", "10.0.0.1:1234", []string{"Forwarded", `for=198.51.100.4;host="api.example.com"`}, "api.example.com"},
+		{"forwarded spoofed element", "10.0.0.1:1234", []string{"Forwarded", "for=1.1.1.1;host=evil.example, for=198.51.100.4;host=api.example.com"}, "api.example.com"},
+		{"forwarded without host", "10.0.0.1:1234", []string{"Forwarded", "for=198.51.100.4"}, "backend.internal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newProxiedRequest(tt.remote, tt.headers...).Host(); got != tt.want {
+				t.Errorf("Host() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
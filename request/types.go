@@ -7,4 +7,8 @@ import "net/http"
 type Request struct {
 	Req        *http.Request
 	parsedForm bool
+	cookies    []*http.Cookie // cookie, которые будут установлены в ответе
+	locale     string         // язык клиента, выбранный по Accept-Language
+	patchType  string         // медиатип применённого патча (см. Patch)
+	patchBody  []byte         // тело применённого патча
 }
@@ -0,0 +1,62 @@
+// Package router реализует простой HTTP-маршрутизатор с поддержкой параметров пути.
+package router
+
+import "server/types"
+
+// Group создаёт группу маршрутов с префиксом пути prefix. Middleware группы выполняются
+// для всех её маршрутов после глобальных middleware и до проверки разрешений.
+func (r *Router) Group(prefix string) *Group {
+	return &Group{router: r, prefix: prefix}
+}
+
+// Group создаёт вложенную группу: префикс и middleware добавляются к родительским.
+func (g *Group) Group(prefix string) *Group {
+	return &Group{router: g.router, parent: g, prefix: g.prefix + prefix}
+}
+
+// Use добавляет middleware группы в порядке выполнения.
+func (g *Group) Use(middlewares ...types.Middleware) *Group {
+	g.middlewares = append(g.middlewares, middlewares...)
+	return g
+}
+
+// Get регистрирует в группе обработчик для HTTP-метода GET.
+func (g *Group) Get(path string, handler types.JsonHandlerFunc) *Handle {
+	return g.register(path, handler, "GET")
+}
+
+// Post регистрирует в группе обработчик для HTTP-метода POST.
+func (g *Group) Post(path string, handler types.JsonHandlerFunc) *Handle {
+	return g.register(path, handler, "POST")
+}
+
+// Put регистрирует в группе обработчик для HTTP-метода PUT.
+func (g *Group) Put(path string, handler types.JsonHandlerFunc) *Handle {
+	return g.register(path, handler, "PUT")
+}
+
+// Patch регистрирует в группе обработчик для HTTP-метода PATCH.
+func (g *Group) Patch(path string, handler types.JsonHandlerFunc) *Handle {
+	return g.register(path, handler, "PATCH")
+}
+
+// Delete регистрирует в группе обработчик для HTTP-метода DELETE.
+func (g *Group) Delete(path string, handler types.JsonHandlerFunc) *Handle {
+	return g.register(path, handler, "DELETE")
+}
+
+// register регистрирует обработчик по пути с префиксом группы и привязывает его к группе.
+func (g *Group) register(path string, handler types.JsonHandlerFunc, method string) *Handle {
+	h := RegisterRoute(g.router, g.prefix+path, handler, method)
+	h.group = g
+	return h
+}
+
+// allMiddlewares возвращает middleware группы вместе с родительскими, начиная с внешней группы.
+func (g *Group) allMiddlewares() []types.Middleware {
+	var middlewares []types.Middleware
+	if g.parent != nil {
+		middlewares = g.parent.allMiddlewares()
+	}
+	return append(middlewares, g.middlewares...)
+}
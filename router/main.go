@@ -3,11 +3,18 @@ package router
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"server/types"
+	"strings"
+	"time"
 )
 
-const paramsKey contextKey = "params"
+const (
+	paramsKey  contextKey = "params"
+	handleKey  contextKey = "handle"
+	methodsKey contextKey = "methods"
+)
 
 // InitRouter инициализирует новый Router, регистрирует маршруты через переданную функцию routes,
 // и возвращает готовый к использованию маршрутизатор.
@@ -19,66 +26,200 @@ func InitRouter(routes func(r *Router)) (*Router, error) {
 	routes(newRouter)
 	for _, route := range newRouter.routes {
 		rt := route
-
-		newRouter.mux.HandleFunc(rt.pattern, func(w http.ResponseWriter, req *http.Request) {
+		for method, h := range rt.routes {
+			var middlewares []types.Middleware
+			if h.group != nil {
+				middlewares = h.group.allMiddlewares()
+			}
+			if len(h.permissions) > 0 {
+				if newRouter.guard == nil {
+					return nil, fmt.Errorf("%s %s requires permissions but no guard is configured", method, rt.pattern)
+				}
+				middlewares = append(middlewares, newRouter.guard(h.permissions...))
+			}
+			middlewares = append(middlewares, h.middlewares...)
+			if h.transaction {
+				if newRouter.transaction == nil {
+					return nil, fmt.Errorf("%s %s is transactional but no transaction middleware is configured", method, rt.pattern)
+				}
+				middlewares = append(middlewares, newRouter.transaction)
+			}
+			timeout := h.timeout
+			if timeout == 0 {
+				timeout = newRouter.timeout
+			}
+			h.compiled = withTimeout(chain(chain(h.handler, middlewares), newRouter.middlewares), timeout)
+		}
+
+		methods := rt.methods()
+		allow := strings.Join(methods, ", ")
+		notFound := chain(http.NotFound, newRouter.middlewares)
+		methodNotAllowed := chain(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Allow", allow)
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}, newRouter.middlewares)
+		automaticOptions := chain(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Allow", allow)
+			w.WriteHeader(http.StatusNoContent)
+		}, newRouter.middlewares)
+
+		newRouter.mux.HandleFunc(muxPattern(rt.pattern), func(w http.ResponseWriter, req *http.Request) {
 			params, ok := matchAndExtractParams(rt.segments, req.URL.Path)
 			if !ok {
-				http.NotFound(w, req)
+				notFound(w, req)
 				return
 			}
 
-			handler, ok := rt.routes[req.Method]
+			ctx := context.WithValue(req.Context(), methodsKey, methods)
+			handle, ok := rt.routes[req.Method]
 			if !ok {
-				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+				if req.Method == http.MethodOptions {
+					automaticOptions(w, req.WithContext(ctx))
+				} else {
+					methodNotAllowed(w, req.WithContext(ctx))
+				}
 				return
 			}
 
-			ctx := context.WithValue(req.Context(), paramsKey, params)
-			handler(w, req.WithContext(ctx))
+			ctx = context.WithValue(ctx, paramsKey, params)
+			ctx = context.WithValue(ctx, handleKey, handle)
+			handle.compiled(w, req.WithContext(ctx))
 		})
 	}
 
 	return newRouter, nil
 }
 
+// Use добавляет глобальные middleware, которые выполняются для всех маршрутов
+// в порядке добавления. К моменту их вызова обработчик уже выбран и доступен через CurrentHandle.
+func (r *Router) Use(middlewares ...types.Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// Guard задаёт функцию проверки разрешений, объявленных через Handle.Require.
+func (r *Router) Guard(guard GuardFunc) {
+	r.guard = guard
+}
+
+// Transactions задаёт middleware, выполняющий в транзакции обработчики,
+// объявленные через Handle.Transactional.
+func (r *Router) Transactions(transaction types.Middleware) {
+	r.transaction = transaction
+}
+
+// Timeout задаёт ограничение времени обработки запроса для маршрутов без собственного
+// ограничения (см. Handle.Timeout). Ноль снимает ограничение.
+func (r *Router) Timeout(timeout time.Duration) {
+	r.timeout = timeout
+}
+
 // Get регистрирует обработчик для HTTP-метода GET по указанному пути.
-func (r *Router) Get(path string, handler types.JsonHandlerFunc) {
-	RegisterRoute(r, path, handler, "GET")
+func (r *Router) Get(path string, handler types.JsonHandlerFunc) *Handle {
+	return RegisterRoute(r, path, handler, "GET")
 }
 
 // Post регистрирует обработчик для HTTP-метода POST по указанному пути.
-func (r *Router) Post(path string, handler types.JsonHandlerFunc) {
-	RegisterRoute(r, path, handler, "POST")
+func (r *Router) Post(path string, handler types.JsonHandlerFunc) *Handle {
+	return RegisterRoute(r, path, handler, "POST")
 }
 
 // Put регистрирует обработчик для HTTP-метода PUT по указанному пути.
-func (r *Router) Put(path string, handler types.JsonHandlerFunc) {
-	RegisterRoute(r, path, handler, "PUT")
+func (r *Router) Put(path string, handler types.JsonHandlerFunc) *Handle {
+	return RegisterRoute(r, path, handler, "PUT")
 }
 
 // Patch регистрирует обработчик для HTTP-метода PATCH по указанному пути.
-func (r *Router) Patch(path string, handler types.JsonHandlerFunc) {
-	RegisterRoute(r, path, handler, "PATCH")
+func (r *Router) Patch(path string, handler types.JsonHandlerFunc) *Handle {
+	return RegisterRoute(r, path, handler, "PATCH")
 }
 
 // Delete регистрирует обработчик для HTTP-метода DELETE по указанному пути.
-func (r *Router) Delete(path string, handler types.JsonHandlerFunc) {
-	RegisterRoute(r, path, handler, "DELETE")
+func (r *Router) Delete(path string, handler types.JsonHandlerFunc) *Handle {
+	return RegisterRoute(r, path, handler, "DELETE")
 }
 
 // Options регистрирует обработчик для HTTP-метода OPTIONS по указанному пути.
-func (r *Router) Options(path string, handler types.JsonHandlerFunc) {
-	RegisterRoute(r, path, handler, "OPTIONS")
+func (r *Router) Options(path string, handler types.JsonHandlerFunc) *Handle {
+	return RegisterRoute(r, path, handler, "OPTIONS")
 }
 
 // Head регистрирует обработчик для HTTP-метода HEAD по указанному пути.
-func (r *Router) Head(path string, handler types.JsonHandlerFunc) {
-	RegisterRoute(r, path, handler, "HEAD")
+func (r *Router) Head(path string, handler types.JsonHandlerFunc) *Handle {
+	return RegisterRoute(r, path, handler, "HEAD")
 }
 
 // Connect регистрирует обработчик для HTTP-метода CONNECT по указанному пути.
-func (r *Router) Connect(path string, handler types.JsonHandlerFunc) {
-	RegisterRoute(r, path, handler, "CONNECT")
+func (r *Router) Connect(path string, handler types.JsonHandlerFunc) *Handle {
+	return RegisterRoute(r, path, handler, "CONNECT")
+}
+
+// Use добавляет middleware, которые выполняются только для этого обработчика
+// после глобальных middleware маршрутизатора, middleware группы и проверки разрешений.
+func (h *Handle) Use(middlewares ...types.Middleware) *Handle {
+	h.middlewares = append(h.middlewares, middlewares...)
+	return h
+}
+
+// Require объявляет разрешения, необходимые для вызова обработчика, например "beer:delete".
+// Проверка выполняется функцией, заданной через Router.Guard, до остальных middleware обработчика.
+func (h *Handle) Require(permissions ...string) *Handle {
+	h.permissions = append(h.permissions, permissions...)
+	return h
+}
+
+// Transactional выполняет обработчик в транзакции, заданной через Router.Transactions.
+// Транзакция открывается после остальных middleware, непосредственно перед обработчиком.
+func (h *Handle) Transactional() *Handle {
+	h.transaction = true
+	return h
+}
+
+// Timeout ограничивает время обработки запроса: по истечении timeout контекст запроса
+// отменяется вместе с выполняемыми в нём запросами к базе данных, а ответ с ошибкой
+// заменяется ответом 504. Ограничение действует и на глобальные middleware.
+func (h *Handle) Timeout(timeout time.Duration) *Handle {
+	h.timeout = timeout
+	return h
+}
+
+// Set сохраняет в обработчике произвольное значение настройки по ключу key.
+// Middleware получают его через CurrentHandle(r).Value(key).
+func (h *Handle) Set(key, value any) *Handle {
+	if h.values == nil {
+		h.values = make(map[any]any)
+	}
+	h.values[key] = value
+	return h
+}
+
+// Value возвращает значение настройки обработчика по ключу key, или nil.
+func (h *Handle) Value(key any) any {
+	return h.values[key]
+}
+
+// Pattern возвращает шаблон пути маршрута, например "/beer/{id}".
+func (h *Handle) Pattern() string {
+	return h.pattern
+}
+
+// Method возвращает HTTP-метод обработчика.
+func (h *Handle) Method() string {
+	return h.method
+}
+
+// CurrentHandle возвращает обработчик, выбранный для запроса, или nil,
+// если путь или метод не найдены.
+func CurrentHandle(r *http.Request) *Handle {
+	h, _ := r.Context().Value(handleKey).(*Handle)
+	return h
+}
+
+// AllowedMethods возвращает HTTP-методы, зарегистрированные для пути запроса,
+// включая OPTIONS, на который маршрутизатор отвечает автоматически.
+// Возвращает nil, если путь не найден.
+func AllowedMethods(r *http.Request) []string {
+	methods, _ := r.Context().Value(methodsKey).([]string)
+	return methods
 }
 
 // ServeHTTP реализует интерфейс http.Handler и передаёт обработку запросов внутреннему mux.
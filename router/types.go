@@ -4,6 +4,7 @@ package router
 import (
 	"net/http"
 	"server/types"
+	"time"
 )
 
 // contextKey используется для хранения значений в контексте HTTP-запроса.
@@ -11,13 +12,49 @@ type contextKey string
 
 // Route описывает один маршрут с шаблоном пути, сегментами и обработчиками по HTTP-методам.
 type Route struct {
-	pattern  string                       // шаблон маршрута, например "/beer/{id}"
-	segments []string                     // сегменты пути, разбитые по "/"
-	routes   map[string]types.HandlerFunc // обработчики для HTTP-методов (GET, POST и др.)
+	pattern  string             // шаблон маршрута, например "/beer/{id}"
+	segments []string           // сегменты пути, разбитые по "/"
+	routes   map[string]*Handle // обработчики для HTTP-методов (GET, POST и др.)
 }
 
+// Handle описывает обработчик одного HTTP-метода маршрута вместе с его middleware.
+type Handle struct {
+	pattern     string             // шаблон пути маршрута
+	method      string             // HTTP-метод обработчика
+	handler     types.HandlerFunc  // исходный обработчик
+	middlewares []types.Middleware // middleware, применяемые только к этому обработчику
+	permissions []string           // разрешения, необходимые для вызова обработчика
+	values      map[any]any        // произвольные настройки обработчика, см. Handle.Set
+	group       *Group             // группа, в которой зарегистрирован обработчик, или nil
+	transaction bool               // выполнять обработчик в транзакции, см. Handle.Transactional
+	timeout     time.Duration      // ограничение времени обработки запроса, см. Handle.Timeout
+	compiled    types.HandlerFunc  // обработчик, обёрнутый в middleware при инициализации роутера
+}
+
+// Group описывает группу маршрутов с общим префиксом пути и общими middleware.
+type Group struct {
+	router      *Router            // маршрутизатор, в котором регистрируются маршруты группы
+	parent      *Group             // родительская группа, или nil
+	prefix      string             // префикс пути маршрутов группы
+	middlewares []types.Middleware // middleware, применяемые ко всем маршрутам группы
+}
+
+// ErrorRecorder реализуется ResponseWriter, которому нужно знать, что обработчик вернул
+// ответ со статусом "error" (например, чтобы откатить транзакцию). WriteJson вызывает
+// RecordError перед записью такого ответа.
+type ErrorRecorder interface {
+	RecordError()
+}
+
+// GuardFunc создаёт middleware, проверяющий наличие у клиента указанных разрешений.
+type GuardFunc func(permissions ...string) types.Middleware
+
 // Router содержит список маршрутов и внутренний HTTP-мультиплексор.
 type Router struct {
-	routes []Route        // список зарегистрированных маршрутов
-	mux    *http.ServeMux // стандартный HTTP-мультиплексор для обработки запросов
+	routes      []Route            // список зарегистрированных маршрутов
+	middlewares []types.Middleware // глобальные middleware, применяемые ко всем маршрутам
+	guard       GuardFunc          // проверка разрешений, объявленных через Handle.Require
+	transaction types.Middleware   // транзакция для обработчиков, объявленных через Handle.Transactional
+	timeout     time.Duration      // ограничение времени обработки запроса по умолчанию, см. Router.Timeout
+	mux         *http.ServeMux     // стандартный HTTP-мультиплексор для обработки запросов
 }
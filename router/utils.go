@@ -2,42 +2,94 @@
 package router
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"server/request"
 	"server/types"
+	"sort"
 	"strings"
+	"time"
 )
 
 // baseRoute регистрирует маршрут с указанным HTTP-методом и обработчиком в маршрутизаторе.
 // Если маршрут с таким путем уже существует, добавляет или обновляет обработчик для метода.
-func baseRoute(r *Router, path string, handler types.HandlerFunc, method string) {
+// Возвращает Handle для дальнейшей настройки обработчика.
+func baseRoute(r *Router, path string, handler types.HandlerFunc, method string) *Handle {
+	h := &Handle{pattern: path, method: method, handler: handler}
 	segments := splitPath(path)
 	for i := range r.routes {
 		if r.routes[i].pattern == path {
 			if r.routes[i].routes == nil {
-				r.routes[i].routes = make(map[string]types.HandlerFunc)
+				r.routes[i].routes = make(map[string]*Handle)
 			}
-			r.routes[i].routes[method] = handler
-			return
+			r.routes[i].routes[method] = h
+			return h
 		}
 	}
 
 	r.routes = append(r.routes, Route{
 		pattern:  path,
 		segments: segments,
-		routes:   map[string]types.HandlerFunc{method: handler},
+		routes:   map[string]*Handle{method: h},
 	})
+	return h
+}
+
+// methods возвращает отсортированный список методов маршрута, дополненный OPTIONS.
+func (rt *Route) methods() []string {
+	methods := make([]string, 0, len(rt.routes)+1)
+	for method := range rt.routes {
+		methods = append(methods, method)
+	}
+	if _, ok := rt.routes[http.MethodOptions]; !ok {
+		methods = append(methods, http.MethodOptions)
+	}
+	sort.Strings(methods)
+	return methods
 }
 
 // RegisterRoute регистрирует маршрут с указанным HTTP-методом и обработчиком типа JsonHandlerFunc.
 // Паника происходит, если переданный обработчик не соответствует типу JsonHandlerFunc.
-func RegisterRoute(r *Router, path string, handler any, method string) {
+func RegisterRoute(r *Router, path string, handler any, method string) *Handle {
 	h, ok := handler.(types.JsonHandlerFunc)
 	if !ok {
 		panic("handler должен быть типа JsonHandlerFunc для маршрутов с параметрами")
 	}
-	baseRoute(r, path, JsonHandlerWrapper(h), method)
+	return baseRoute(r, path, JsonHandlerWrapper(h), method)
+}
+
+// chain оборачивает обработчик в middleware так, что первый из них выполняется первым.
+func chain(handler types.HandlerFunc, middlewares []types.Middleware) types.HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// withTimeout ограничивает время обработки запроса обработчиком handler.
+// При timeout <= 0 возвращает handler без изменений.
+func withTimeout(handler types.HandlerFunc, timeout time.Duration) types.HandlerFunc {
+	if timeout <= 0 {
+		return handler
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		handler(w, r.WithContext(ctx))
+	}
+}
+
+// muxPattern возвращает шаблон для http.ServeMux. Маршрут с завершающим слешем
+// регистрируется как точный ("/beer/random/{$}"), а не как поддерево: маршрутизатор
+// сопоставляет пути посегментно, а поддерево конфликтовало бы с маршрутами
+// вида "/beer/{id}/restore".
+func muxPattern(pattern string) string {
+	if strings.HasSuffix(pattern, "/") {
+		return pattern + "{$}"
+	}
+	return pattern
 }
 
 // matchAndExtractParams проверяет соответствие сегментов пути шаблону маршрута,
@@ -83,15 +135,46 @@ func GetParams(r *http.Request) map[string]string {
 
 // JsonHandlerWrapper оборачивает JsonHandlerFunc в стандартный http.HandlerFunc,
 // обеспечивая парсинг параметров, инициализацию запроса и отправку JSON-ответа.
+// Ошибка обработчика, вызванная истечением времени запроса, возвращается как 504.
 func JsonHandlerWrapper(handler types.JsonHandlerFunc) types.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		params := GetParams(r)
 		req := request.InitRequest(r)
 		response := handler(req, params)
+		if response.Status == "error" && errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+			response = types.JsonResponse{Status: "error", Message: req.T("request.timeout"), Code: http.StatusGatewayTimeout}
+		}
+
+		for _, c := range req.ResponseCookies() {
+			http.SetCookie(w, c)
+		}
+		WriteJson(w, r, response)
+	}
+}
 
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+// WriteJson отправляет JSON-ответ с HTTP-кодом response.Code (200, если он не задан).
+// К ответам с ошибкой добавляется идентификатор запроса, к заголовкам — response.Header.
+func WriteJson(w http.ResponseWriter, r *http.Request, response types.JsonResponse) {
+	if response.Status == "error" {
+		response.RequestID = request.IDFromContext(r.Context())
+		if recorder, ok := w.(ErrorRecorder); ok {
+			recorder.RecordError()
 		}
 	}
+	code := response.Code
+	if code == 0 {
+		code = http.StatusOK
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for key, values := range response.Header {
+		w.Header()[key] = values
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(append(body, '\n'))
 }
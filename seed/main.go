@@ -0,0 +1,163 @@
+// Package seed загружает наборы тестовых данных (фикстуры) в базу данных.
+package seed
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"server/models"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// bundled содержит встроенные наборы фикстур: каталог fixtures/<набор>.
+//
+//go:embed fixtures
+var bundled embed.FS
+
+// EnvSets сопоставляет окружениям (APP_ENV) наборы фикстур по умолчанию.
+var EnvSets = map[string]string{
+	"development": "demo",
+	"test":        "test",
+}
+
+// Bundled возвращает файловую систему встроенных наборов фикстур.
+func Bundled() fs.FS {
+	sub, _ := fs.Sub(bundled, "fixtures")
+	return sub
+}
+
+// Sets возвращает имена наборов фикстур — подкаталогов fsys.
+func Sets(fsys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	var sets []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			sets = append(sets, entry.Name())
+		}
+	}
+	return sets, nil
+}
+
+// Load читает все файлы .yaml, .yml и .json набора set из fsys в алфавитном порядке.
+func Load(fsys fs.FS, set string) (*Fixtures, error) {
+	entries, err := fs.ReadDir(fsys, set)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("fixture set %q not found", set)
+		}
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	fixtures := &Fixtures{}
+	for _, entry := range entries {
+		name := path.Join(set, entry.Name())
+		if entry.IsDir() {
+			continue
+		}
+
+		var unmarshal func([]byte, any) error
+		switch strings.ToLower(path.Ext(name)) {
+		case ".yaml", ".yml":
+			unmarshal = yaml.Unmarshal
+		case ".json":
+			unmarshal = json.Unmarshal
+		default:
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		var file Fixtures
+		if err := unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		fixtures.Beers = append(fixtures.Beers, file.Beers...)
+		fixtures.Snacks = append(fixtures.Snacks, file.Snacks...)
+	}
+	return fixtures, nil
+}
+
+// Apply сохраняет фикстуры в одной транзакции: записи с тем же естественным ключом
+// обновляются (мягко удалённые — восстанавливаются), остальные создаются,
+// поэтому повторный запуск не создаёт дубликатов.
+func Apply(db *gorm.DB, fixtures *Fixtures) (Result, error) {
+	var result Result
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, f := range fixtures.Beers {
+			beer := models.Beer{
+				Name: f.Name, Brewery: f.Brewery, Style: f.Style, Alcohol: f.Alcohol,
+				Description: f.Description, IBU: f.IBU, EBC: f.EBC,
+			}
+			created, err := upsert(tx, &beer, "name = ? AND brewery = ?", f.Name, f.Brewery)
+			if err != nil {
+				return fmt.Errorf("beer %q: %w", f.Name, err)
+			}
+			result.count(created)
+		}
+		for _, f := range fixtures.Snacks {
+			snack := models.Snack{
+				Name: f.Name, Type: f.Type, Description: f.Description, Country: f.Country,
+				Calories: f.Calories, Spicy: f.Spicy, Vegetarian: f.Vegetarian,
+			}
+			created, err := upsert(tx, &snack, "name = ? AND country = ?", f.Name, f.Country)
+			if err != nil {
+				return fmt.Errorf("snack %q: %w", f.Name, err)
+			}
+			result.count(created)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// validatable — модель с проверкой полей перед сохранением.
+type validatable interface {
+	Validate() error
+}
+
+// upsert проверяет запись и создаёт её или обновляет найденную по условию key,
+// увеличивая версию обновлённой записи. Поиск учитывает мягко удалённые записи:
+// такая запись восстанавливается, а не дублируется.
+// Возвращает true, если запись создана.
+func upsert(tx *gorm.DB, record validatable, key string, args ...any) (bool, error) {
+	if err := record.Validate(); err != nil {
+		return false, err
+	}
+
+	var id uint
+	err := tx.Unscoped().Model(record).Select("id").Where(key, args...).Limit(1).Scan(&id).Error
+	if err != nil {
+		return false, err
+	}
+	if id == 0 {
+		return true, tx.Create(record).Error
+	}
+	// Нулевой deleted_at записи record снимает мягкое удаление
+	err = tx.Unscoped().Model(record).Where("id = ?", id).Select("*").Omit("id", "created_at", "version").Updates(record).Error
+	if err != nil {
+		return false, err
+	}
+	return false, tx.Unscoped().Model(record).Where("id = ?", id).UpdateColumn("version", gorm.Expr("version + 1")).Error
+}
+
+// count учитывает созданную или обновлённую запись.
+func (r *Result) count(created bool) {
+	if created {
+		r.Created++
+	} else {
+		r.Updated++
+	}
+}
@@ -0,0 +1,76 @@
+package seed
+
+import (
+	"server/database/dbtest"
+	"server/migrate"
+	"server/migrations"
+	"server/models"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// openDB открывает тестовую базу SQLite в памяти и применяет к ней миграции приложения.
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := dbtest.Open(t)
+	if _, err := migrate.New(db, migrations.All()).Up(0); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestApplyIsIdempotent(t *testing.T) {
+	db := openDB(t)
+	fixtures := &Fixtures{
+		Beers:  []Beer{{Name: "Porter", Brewery: "Baltika", Alcohol: 8}},
+		Snacks: []Snack{{Name: "Vobla", Country: "Russia"}},
+	}
+
+	result, err := Apply(db, fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (Result{Created: 2}) {
+		t.Fatalf("first run: %+v", result)
+	}
+
+	fixtures.Beers[0].Alcohol = 7
+	if result, err = Apply(db, fixtures); err != nil {
+		t.Fatal(err)
+	}
+	if result != (Result{Updated: 2}) {
+		t.Fatalf("second run: %+v", result)
+	}
+
+	var beers []models.Beer
+	db.Find(&beers)
+	if len(beers) != 1 || beers[0].Alcohol != 7 || beers[0].Version != 2 {
+		t.Fatalf("unexpected beers: %+v", beers)
+	}
+}
+
+func TestApplyRestoresDeleted(t *testing.T) {
+	db := openDB(t)
+	fixtures := &Fixtures{Beers: []Beer{{Name: "Porter", Brewery: "Baltika"}}}
+	if _, err := Apply(db, fixtures); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Where("name = ?", "Porter").Delete(&models.Beer{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Apply(db, fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (Result{Updated: 1}) {
+		t.Fatalf("expected the deleted beer to be updated, got %+v", result)
+	}
+
+	var beers []models.Beer
+	db.Unscoped().Find(&beers)
+	if len(beers) != 1 || beers[0].DeletedAt.Valid || beers[0].Version != 2 {
+		t.Fatalf("expected one restored beer, got %+v", beers)
+	}
+}
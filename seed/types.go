@@ -0,0 +1,36 @@
+// Package seed загружает наборы тестовых данных (фикстуры) в базу данных.
+package seed
+
+// Fixtures — содержимое файлов фикстур одного набора.
+type Fixtures struct {
+	Beers  []Beer  `yaml:"beers" json:"beers"`
+	Snacks []Snack `yaml:"snacks" json:"snacks"`
+}
+
+// Beer — фикстура пива. Естественный ключ — название и пивоварня.
+type Beer struct {
+	Name        string  `yaml:"name" json:"name"`
+	Brewery     string  `yaml:"brewery" json:"brewery"`
+	Style       string  `yaml:"style" json:"style"`
+	Alcohol     float32 `yaml:"alcohol" json:"alcohol"`
+	Description string  `yaml:"description" json:"description"`
+	IBU         int     `yaml:"ibu" json:"ibu"`
+	EBC         int     `yaml:"ebc" json:"ebc"`
+}
+
+// Snack — фикстура закуски. Естественный ключ — название и страна.
+type Snack struct {
+	Name        string `yaml:"name" json:"name"`
+	Type        string `yaml:"type" json:"type"`
+	Description string `yaml:"description" json:"description"`
+	Country     string `yaml:"country" json:"country"`
+	Calories    int    `yaml:"calories" json:"calories"`
+	Spicy       bool   `yaml:"spicy" json:"spicy"`
+	Vegetarian  bool   `yaml:"vegetarian" json:"vegetarian"`
+}
+
+// Result — количество созданных и обновлённых записей.
+type Result struct {
+	Created int
+	Updated int
+}
@@ -0,0 +1,81 @@
+// Package server содержит функции инициализации и запуска HTTP-сервера.
+package server
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"log"
+	"os"
+	"server/auth"
+	"server/config"
+	"server/database"
+	"time"
+)
+
+// signatureVerifier проверяет подписи запросов интеграций.
+var signatureVerifier *auth.SignatureVerifier
+
+// initAuth настраивает выпуск JWT по переменным окружения:
+// JWT_HMAC_SECRET или JWT_HMAC_SECRET_FILE (секрет HS256), JWT_ED25519_KEY_FILE (путь к закрытому ключу Ed25519 в PEM/PKCS#8),
+// JWT_ISSUER, JWT_ACCESS_TTL и JWT_REFRESH_TTL, а также допустимое расхождение
+// времени подписанных запросов SIGNATURE_MAX_SKEW.
+func initAuth() error {
+	signatureVerifier = auth.NewSignatureVerifier(database.DB, auth.NewMemoryNonceStore(),
+		config.Duration("SIGNATURE_MAX_SKEW", 5*time.Minute))
+
+	hmacSecret, err := config.Secret("JWT_HMAC_SECRET", "")
+	if err != nil {
+		return err
+	}
+	tokenConfig := auth.TokenConfig{
+		Issuer:     config.String("JWT_ISSUER", "go-server"),
+		AccessTTL:  config.Duration("JWT_ACCESS_TTL", 15*time.Minute),
+		RefreshTTL: config.Duration("JWT_REFRESH_TTL", 30*24*time.Hour),
+		HMACSecret: []byte(hmacSecret),
+	}
+
+	if path := config.String("JWT_ED25519_KEY_FILE", ""); path != "" {
+		key, err := loadEd25519Key(path)
+		if err != nil {
+			return err
+		}
+		tokenConfig.Ed25519Key = key
+	}
+
+	if tokenConfig.Ed25519Key == nil && len(tokenConfig.HMACSecret) == 0 {
+		log.Println("JWT_HMAC_SECRET is not set, using a random key: tokens will not survive restarts")
+		tokenConfig.HMACSecret = make([]byte, 32)
+		if _, err := rand.Read(tokenConfig.HMACSecret); err != nil {
+			return err
+		}
+	}
+	if len(tokenConfig.HMACSecret) > 0 && len(tokenConfig.HMACSecret) < 32 {
+		return errors.New("JWT_HMAC_SECRET must be at least 32 bytes")
+	}
+
+	return auth.InitTokens(database.DB, tokenConfig)
+}
+
+// loadEd25519Key читает закрытый ключ Ed25519 из PEM-файла в формате PKCS#8.
+func loadEd25519Key(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("JWT_ED25519_KEY_FILE: no PEM block found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("JWT_ED25519_KEY_FILE: not an Ed25519 private key")
+	}
+	return edKey, nil
+}
@@ -0,0 +1,50 @@
+// Package server содержит функции инициализации и запуска HTTP-сервера.
+package server
+
+import (
+	"net/http"
+	"server/database"
+	"server/request"
+	"server/router"
+	"server/types"
+	"sync/atomic"
+)
+
+// api — маршрутизатор API; nil, пока сервер не готов.
+var api atomic.Pointer[router.Router]
+
+// serveHTTP обслуживает проверки состояния и передаёт остальные запросы маршрутизатору API.
+// /healthz отвечает 200, пока процесс работает. /readyz отвечает 200, только когда сервер
+// подготовлен и соединение с базой данных исправно (см. database.WatchHealth), иначе 503.
+// Пока сервер не готов, маршруты API отвечают 503 с заголовком Retry-After.
+func serveHTTP(w http.ResponseWriter, r *http.Request) {
+	apiRouter := api.Load()
+	switch r.URL.Path {
+	case "/healthz":
+		router.WriteJson(w, r, types.JsonResponse{Status: "success"})
+		return
+	case "/readyz":
+		readiness(w, r, apiRouter != nil)
+		return
+	}
+
+	if apiRouter == nil {
+		w.Header().Set("Retry-After", "5")
+		router.WriteJson(w, r, types.JsonResponse{Status: "error", Message: request.InitRequest(r).T("server.starting"), Code: http.StatusServiceUnavailable})
+		return
+	}
+	apiRouter.ServeHTTP(w, r)
+}
+
+// readiness отвечает на проверку готовности сервера.
+func readiness(w http.ResponseWriter, r *http.Request, ready bool) {
+	req := request.InitRequest(r)
+	switch {
+	case !ready:
+		router.WriteJson(w, r, types.JsonResponse{Status: "error", Message: req.T("server.starting"), Code: http.StatusServiceUnavailable})
+	case !database.Healthy():
+		router.WriteJson(w, r, types.JsonResponse{Status: "error", Message: req.T("server.database_unavailable"), Code: http.StatusServiceUnavailable})
+	default:
+		router.WriteJson(w, r, types.JsonResponse{Status: "success"})
+	}
+}
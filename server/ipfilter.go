@@ -0,0 +1,68 @@
+// Package server содержит функции инициализации и запуска HTTP-сервера.
+package server
+
+import (
+	"log"
+	"server/config"
+	"server/ipfilter"
+	"time"
+)
+
+var (
+	// globalIPFilter ограничивает доступ ко всем маршрутам.
+	globalIPFilter *ipfilter.Filter
+	// adminIPFilter ограничивает доступ к административным маршрутам.
+	adminIPFilter *ipfilter.Filter
+)
+
+// defaultAdminRules возвращает правила фильтра admin, если они не настроены.
+// С доверенными прокси (TRUSTED_PROXIES) административные маршруты доступны только
+// с локального адреса. Без них маршруты закрыты для всех: за прокси на том же хосте
+// любой запрос приходит с 127.0.0.1, и правило «только локальный адрес» пропустило бы всех.
+func defaultAdminRules(trustedProxies bool) ipfilter.Rules {
+	if trustedProxies {
+		return ipfilter.Rules{Allow: []string{"127.0.0.0/8", "::1"}}
+	}
+	return ipfilter.Rules{Deny: []string{"0.0.0.0/0", "::/0"}}
+}
+
+// initIPFilters настраивает фильтры IP-адресов. Если задана переменная IP_FILTER_FILE,
+// правила читаются из JSON-файла вида {"global": {"allow": [...], "deny": [...]}, "admin": {...}}
+// и перечитываются при его изменении (проверка раз в IP_FILTER_RELOAD_INTERVAL) или по SIGHUP.
+// Иначе правила берутся из переменных IP_ALLOW, IP_DENY, ADMIN_IP_ALLOW и ADMIN_IP_DENY.
+// Если правила фильтра admin не заданы, действуют defaultAdminRules.
+func initIPFilters() error {
+	defaults := defaultAdminRules(len(config.List("TRUSTED_PROXIES")) > 0)
+	var err error
+	globalIPFilter, err = ipfilter.New(ipfilter.Rules{Allow: config.List("IP_ALLOW"), Deny: config.List("IP_DENY")})
+	if err != nil {
+		return err
+	}
+	path := config.String("IP_FILTER_FILE", "")
+	adminRules := ipfilter.Rules{Allow: config.List("ADMIN_IP_ALLOW"), Deny: config.List("ADMIN_IP_DENY")}
+	if path == "" && len(adminRules.Allow) == 0 && len(adminRules.Deny) == 0 {
+		if len(defaults.Allow) > 0 {
+			log.Printf("ADMIN_IP_ALLOW is not set: admin routes are reachable only from %v", defaults.Allow)
+		} else {
+			log.Printf("Neither ADMIN_IP_ALLOW nor TRUSTED_PROXIES is set: admin routes are disabled")
+		}
+		adminRules = defaults
+	}
+	adminIPFilter, err = ipfilter.New(adminRules)
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		return nil
+	}
+	reloader := ipfilter.NewReloader(path, map[string]*ipfilter.Filter{
+		"global": globalIPFilter,
+		"admin":  adminIPFilter,
+	}).WithDefaults(ipfilter.Config{"admin": defaults})
+	if err := reloader.Reload(); err != nil {
+		return err
+	}
+	reloader.Watch(config.Duration("IP_FILTER_RELOAD_INTERVAL", 10*time.Second))
+	return nil
+}
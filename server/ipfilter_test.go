@@ -0,0 +1,31 @@
+package server
+
+import (
+	"net"
+	"server/ipfilter"
+	"testing"
+)
+
+func TestDefaultAdminRules(t *testing.T) {
+	tests := []struct {
+		trustedProxies bool
+		ip             string
+		want           bool
+	}{
+		{false, "127.0.0.1", false},
+		{false, "::1", false},
+		{false, "203.0.113.7", false},
+		{true, "127.0.0.1", true},
+		{true, "::1", true},
+		{true, "203.0.113.7", false},
+	}
+	for _, tt := range tests {
+		f, err := ipfilter.New(defaultAdminRules(tt.trustedProxies))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := f.Allowed(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("trusted proxies %v: Allowed(%s) = %v, want %v", tt.trustedProxies, tt.ip, got, tt.want)
+		}
+	}
+}
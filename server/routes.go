@@ -2,29 +2,82 @@
 package server
 
 import (
+	"server/auth"
 	"server/controllers"
+	"server/database"
+	"server/middleware"
+	"server/ratelimit"
+	"server/rbac"
 	"server/router"
+	"server/session"
+	"time"
 )
 
 // routes регистрирует все маршруты HTTP-сервера и связывает их с соответствующими контроллерами.
+// Контроллеры получают хранилища из контейнера приложения container.
 func routes(r *router.Router) {
+	beers := controllers.NewBeerController(container.Beers)
+	snacks := controllers.NewSnackController(container.Snacks)
+	audit := controllers.NewAuditController(container.Audit)
+
+	r.Use(
+		middleware.RequestID(),
+		middleware.SecurityHeaders(securityPolicy),
+		globalIPFilter.Middleware(),
+		middleware.CORS(corsPolicy),
+		ipRateLimiter.Middleware(),
+		session.Middleware(sessionStore),
+		auth.Middleware(
+			auth.NewAPIKeyAuthenticator(database.DB),
+			auth.NewJWTAuthenticator(auth.Tokens),
+		),
+		signatureVerifier.Middleware(),
+		middleware.NoStore(securityPolicy),
+	)
+	r.Use(rateLimiter.Middleware(), middleware.ReadReplicas(replicaStickiness))
+	r.Guard(rbac.NewAuthorizer(database.DB, 30*time.Second).Require)
+	r.Transactions(middleware.Transaction())
+	r.Timeout(requestTimeout)
+
+	// Административные маршруты доступны только из сетей, разрешённых фильтром admin
+	admin := r.Group("").Use(adminIPFilter.Middleware())
+
+	// Лимит и ограничение времени для тяжёлых запросов со случайной выборкой (ORDER BY RAND()/RANDOM())
+	randomLimit := ratelimit.Limit{Requests: 10, Window: time.Minute}
+	randomTimeout := 5 * time.Second
+
+	// Маршруты аутентификации пользователей
+	r.Post("/auth/login", controllers.Login)
+	r.Post("/auth/refresh", controllers.RefreshToken)
+	r.Post("/auth/logout", controllers.Logout)
+
 	// Маршруты для работы с пивом (Beer)
-	r.Get("/beer/random/", controllers.GetRandomBeer)
-	r.Post("/beer/", controllers.StoreBeer)
-	r.Get("/beer/{id}", controllers.ShowBeer)
-	r.Put("/beer/{id}", controllers.UpdateBeer)
-	r.Patch("/beer/{id}", controllers.UpdateBeer)
-	r.Delete("/beer/{id}", controllers.DeleteBeer)
+	r.Get("/beer/random/", beers.GetRandomBeer).Require("beer:read").Set(ratelimit.RouteKey, randomLimit).Timeout(randomTimeout)
+	r.Get("/beers/", beers.GetAllBeers).Require("beer:read")
+	r.Post("/beer/", beers.StoreBeer).Require("beer:create").Transactional()
+	r.Get("/beer/{id}", beers.ShowBeer).Require("beer:read")
+	r.Put("/beer/{id}", beers.UpdateBeer).Require("beer:update").Transactional()
+	r.Patch("/beer/{id}", beers.PatchBeer).Require("beer:update").Transactional()
+	admin.Delete("/beer/{id}", beers.DeleteBeer).Require("beer:delete").Transactional()
+	admin.Get("/beer/trash", beers.GetTrashedBeers).Require("beer:delete")
+	admin.Post("/beer/{id}/restore", beers.RestoreBeer).Require("beer:delete").Transactional()
+	admin.Delete("/beer/{id}/force", beers.ForceDeleteBeer).Require("beer:purge").Transactional()
 
 	// Маршруты для работы с закусками (Snack)
-	r.Post("/snack/", controllers.CreateSnack)
-	r.Get("/snack/random/", controllers.GetRandomSnack)
-	r.Get("/snack/{id}", controllers.GetSnack)
-	r.Get("/snacks/", controllers.GetAllSnacks)
-	r.Put("/snack/{id}", controllers.UpdateSnack)
-	r.Patch("/snack/{id}", controllers.UpdateSnack)
-	r.Delete("/snack/{id}", controllers.DeleteSnack)
+	r.Post("/snack/", snacks.CreateSnack).Require("snack:create").Transactional()
+	r.Get("/snack/random/", snacks.GetRandomSnack).Require("snack:read").Set(ratelimit.RouteKey, randomLimit).Timeout(randomTimeout)
+	r.Get("/snack/{id}", snacks.GetSnack).Require("snack:read")
+	r.Get("/snacks/", snacks.GetAllSnacks).Require("snack:read")
+	r.Put("/snack/{id}", snacks.UpdateSnack).Require("snack:update").Transactional()
+	r.Patch("/snack/{id}", snacks.PatchSnack).Require("snack:update").Transactional()
+	admin.Delete("/snack/{id}", snacks.DeleteSnack).Require("snack:delete").Transactional()
+	admin.Get("/snack/trash", snacks.GetTrashedSnacks).Require("snack:delete")
+	admin.Post("/snack/{id}/restore", snacks.RestoreSnack).Require("snack:delete").Transactional()
+	admin.Delete("/snack/{id}/force", snacks.ForceDeleteSnack).Require("snack:purge").Transactional()
+
+	// Журнал изменений
+	admin.Get("/audit/", audit.GetAuditEntries).Require("audit:read")
 
 	// Дополнительный маршрут
-	r.Get("/hohol/", controllers.GetRandomBeer)
+	r.Get("/hohol/", beers.GetRandomBeer).Require("beer:read").Set(ratelimit.RouteKey, randomLimit).Timeout(randomTimeout)
 }
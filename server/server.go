@@ -5,19 +5,118 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"server/app"
+	"server/config"
+	"server/database"
+	"server/middleware"
+	"server/ratelimit"
+	"server/request"
 	"server/router"
+	"time"
 )
 
-// Init инициализирует роутер, регистрирует маршруты и запускает HTTP-сервер на порту 8000.
-// В случае ошибки регистрации маршрутов или запуска сервера происходит логирование и завершение работы.
-func Init() {
-	apiRouter, err := router.InitRouter(routes)
-	if err != nil {
-		log.Fatalf("Error registering handlers: %v", err)
-	}
+var (
+	// container содержит зависимости контроллеров.
+	container *app.Container
+	// corsPolicy задаёт правила CORS для фронтендов на других источниках.
+	corsPolicy middleware.CORSPolicy
+	// securityPolicy задаёт заголовки безопасности ответов по умолчанию.
+	securityPolicy middleware.SecurityPolicy
+	// rateLimiter ограничивает частоту запросов клиентов.
+	rateLimiter *ratelimit.Limiter
+	// ipRateLimiter ограничивает частоту запросов с одного IP-адреса до аутентификации.
+	ipRateLimiter *ratelimit.Limiter
+	// requestTimeout — ограничение времени обработки запроса по умолчанию.
+	requestTimeout time.Duration
+	// replicaStickiness — время, в течение которого чтения клиента после записи идут на основную базу.
+	replicaStickiness time.Duration
+)
+
+// Init запускает HTTP-сервер на порту 8000 в состоянии «не готов» и выполняет prepare —
+// подключение к базе данных и проверку схемы. Затем инициализирует маршрутизатор и начинает
+// обслуживать маршруты API. До этого /readyz и маршруты API отвечают 503, а /healthz — 200,
+// пока процесс жив (см. serveHTTP).
+// Список доверенных прокси берётся из переменной окружения TRUSTED_PROXIES (CIDR через запятую),
+// фильтры IP-адресов, хранилище сессий, выпуск токенов, подпись запросов, заголовки безопасности,
+// CORS, лимит запросов и проверка реплик базы данных настраиваются переменными IP_*, SESSION_*,
+// JWT_*, SIGNATURE_*, SECURITY_*, CORS_*, RATE_LIMIT_* и DB_REPLICA_*, ограничение времени
+// обработки запроса — переменной REQUEST_TIMEOUT, период проверки соединения с базой — DB_HEALTH_INTERVAL,
+// очистка корзины — переменными TRASH_* (см. startTrashPurge).
+// В случае ошибки подготовки, регистрации маршрутов или запуска сервера происходит логирование и завершение работы.
+func Init(prepare func() error) {
+	go func() {
+		if err := prepare(); err != nil {
+			log.Fatalf("Error preparing server: %v", err)
+		}
+		api.Store(initRouter())
+		database.WatchHealth(config.Duration("DB_HEALTH_INTERVAL", 5*time.Second))
+		startTrashPurge()
+		log.Println("Server is ready")
+	}()
 
 	fmt.Println("Server started at http://localhost:8000")
-	if err := http.ListenAndServe(":8000", apiRouter); err != nil {
+	if err := http.ListenAndServe(":8000", http.HandlerFunc(serveHTTP)); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
 }
+
+// initRouter настраивает зависимости маршрутов и возвращает маршрутизатор API.
+func initRouter() *router.Router {
+	container = app.New(database.DB)
+	database.WatchReplicas(config.Duration("DB_REPLICA_CHECK_INTERVAL", 10*time.Second))
+	replicaStickiness = config.Duration("DB_REPLICA_STICKY", 5*time.Second)
+	requestTimeout = config.Duration("REQUEST_TIMEOUT", 30*time.Second)
+
+	if err := request.SetTrustedProxies(config.List("TRUSTED_PROXIES")); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	if err := initIPFilters(); err != nil {
+		log.Fatalf("Error initializing IP filters: %v", err)
+	}
+	if err := initSessions(); err != nil {
+		log.Fatalf("Error initializing sessions: %v", err)
+	}
+	if err := initAuth(); err != nil {
+		log.Fatalf("Error initializing auth: %v", err)
+	}
+	exposedHeaders := config.List("CORS_EXPOSED_HEADERS")
+	if exposedHeaders == nil {
+		exposedHeaders = []string{middleware.RequestIDHeader, "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"}
+	}
+	corsPolicy = middleware.CORSPolicy{
+		AllowedOrigins:   config.List("CORS_ALLOWED_ORIGINS"),
+		AllowedMethods:   config.List("CORS_ALLOWED_METHODS"),
+		AllowedHeaders:   config.List("CORS_ALLOWED_HEADERS"),
+		ExposedHeaders:   exposedHeaders,
+		AllowCredentials: config.Bool("CORS_ALLOW_CREDENTIALS", false),
+		MaxAge:           config.Duration("CORS_MAX_AGE", 10*time.Minute),
+	}
+	if err := corsPolicy.Validate(); err != nil {
+		log.Fatalf("Invalid CORS configuration: %v", err)
+	}
+	defaults := middleware.DefaultSecurityPolicy()
+	securityPolicy = middleware.SecurityPolicy{
+		HSTSMaxAge:            config.Duration("SECURITY_HSTS_MAX_AGE", defaults.HSTSMaxAge),
+		HSTSIncludeSubdomains: config.Bool("SECURITY_HSTS_INCLUDE_SUBDOMAINS", defaults.HSTSIncludeSubdomains),
+		ContentTypeOptions:    defaults.ContentTypeOptions,
+		FrameOptions:          config.String("SECURITY_FRAME_OPTIONS", defaults.FrameOptions),
+		ReferrerPolicy:        config.String("SECURITY_REFERRER_POLICY", defaults.ReferrerPolicy),
+		ContentSecurityPolicy: config.String("SECURITY_CSP", defaults.ContentSecurityPolicy),
+		NoStoreAuthenticated:  defaults.NoStoreAuthenticated,
+	}
+	rateStore := ratelimit.NewMemoryStore()
+	rateLimiter = ratelimit.New(rateStore, ratelimit.Limit{
+		Requests: config.Int("RATE_LIMIT_REQUESTS", 60),
+		Window:   config.Duration("RATE_LIMIT_WINDOW", time.Minute),
+	})
+	ipRateLimiter = ratelimit.NewIP(rateStore, ratelimit.Limit{
+		Requests: config.Int("RATE_LIMIT_IP_REQUESTS", 300),
+		Window:   config.Duration("RATE_LIMIT_IP_WINDOW", time.Minute),
+	})
+
+	apiRouter, err := router.InitRouter(routes)
+	if err != nil {
+		log.Fatalf("Error registering handlers: %v", err)
+	}
+	return apiRouter
+}
@@ -0,0 +1,62 @@
+// Package server содержит функции инициализации и запуска HTTP-сервера.
+package server
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log"
+	"server/config"
+	"server/database"
+	"server/session"
+	"time"
+)
+
+// sessionStore — хранилище сессий, используемое middleware сессий.
+var sessionStore session.Store
+
+// initSessions создаёт хранилище сессий по переменным окружения:
+// SESSION_DRIVER ("cookie" или "database"), SESSION_SECRET (ключи подписи через запятую,
+// первый используется для подписи), SESSION_COOKIE и SESSION_TTL.
+func initSessions() error {
+	options := session.Options{
+		CookieName: config.String("SESSION_COOKIE", "session"),
+		TTL:        config.Duration("SESSION_TTL", 24*time.Hour),
+	}
+
+	switch driver := config.String("SESSION_DRIVER", "cookie"); driver {
+	case "cookie":
+		var keys [][]byte
+		for _, secret := range config.List("SESSION_SECRET") {
+			keys = append(keys, []byte(secret))
+		}
+		if len(keys) == 0 {
+			log.Println("SESSION_SECRET is not set, using a random key: sessions will not survive restarts")
+			key := make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return err
+			}
+			keys = append(keys, key)
+		}
+		store, err := session.NewCookieStore(options, keys...)
+		if err != nil {
+			return err
+		}
+		sessionStore = store
+	case "database":
+		store := session.NewDatabaseStore(database.DB, options)
+		go purgeSessions(store)
+		sessionStore = store
+	default:
+		return fmt.Errorf("unknown session driver %q", driver)
+	}
+	return nil
+}
+
+// purgeSessions периодически удаляет истёкшие сессии из базы данных.
+func purgeSessions(store *session.DatabaseStore) {
+	for range time.Tick(time.Hour) {
+		if _, err := store.PurgeExpired(); err != nil {
+			log.Printf("session: purge failed: %v", err)
+		}
+	}
+}
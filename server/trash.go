@@ -0,0 +1,45 @@
+// Package server содержит функции инициализации и запуска HTTP-сервера.
+package server
+
+import (
+	"context"
+	"log"
+	"server/auth"
+	"server/config"
+	"time"
+)
+
+// startTrashPurge запускает периодическую очистку корзины: записи, мягко удалённые больше
+// TRASH_RETENTION_DAYS дней назад (по умолчанию 30), удаляются окончательно раз в
+// TRASH_PURGE_INTERVAL (по умолчанию час). Нулевой срок хранения отключает очистку.
+func startTrashPurge() {
+	days := config.Int("TRASH_RETENTION_DAYS", 30)
+	interval := config.Duration("TRASH_PURGE_INTERVAL", time.Hour)
+	if days <= 0 || interval <= 0 {
+		return
+	}
+	retention := time.Duration(days) * 24 * time.Hour
+
+	go func() {
+		for ; ; time.Sleep(interval) {
+			purgeTrash(time.Now().Add(-retention))
+		}
+	}()
+}
+
+// purgeTrash окончательно удаляет пиво и закуски, мягко удалённые раньше before.
+// Удаления записываются в журнал изменений от имени системного субъекта "trash-purge".
+func purgeTrash(before time.Time) {
+	ctx := auth.WithPrincipal(context.Background(), auth.SystemPrincipal("trash-purge"))
+	beers, err := container.Beers.Purge(ctx, before)
+	if err != nil {
+		log.Printf("trash: failed to purge beers: %v", err)
+	}
+	snacks, err := container.Snacks.Purge(ctx, before)
+	if err != nil {
+		log.Printf("trash: failed to purge snacks: %v", err)
+	}
+	if beers+snacks > 0 {
+		log.Printf("trash: purged %d beers and %d snacks deleted before %s", beers, snacks, before.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,145 @@
+// Package session реализует серверные сессии клиентов поверх cookie.
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"server/request"
+	"strings"
+	"time"
+)
+
+// maxCookieSize — максимальный размер значения cookie, поддерживаемый браузерами.
+const maxCookieSize = 4096
+
+// ErrCookieTooLarge возвращается, если данные сессии не помещаются в cookie.
+var ErrCookieTooLarge = errors.New("session data exceeds cookie size limit")
+
+// CookieStore хранит данные сессии непосредственно в cookie,
+// подписанной HMAC-SHA256. Данные видны клиенту, но не могут быть им изменены.
+// Выданную cookie нельзя отозвать до истечения её срока, в том числе после Rotate.
+type CookieStore struct {
+	keys    [][]byte
+	options Options
+}
+
+// cookiePayload — содержимое подписанной cookie сессии.
+type cookiePayload struct {
+	ID        string         `json:"id"`
+	Values    map[string]any `json:"v"`
+	ExpiresAt int64          `json:"exp"`
+}
+
+// NewCookieStore создаёт хранилище сессий в подписанных cookie.
+// Первый ключ используется для подписи, все ключи — для проверки,
+// что позволяет выполнять ротацию ключей без сброса сессий.
+func NewCookieStore(options Options, keys ...[]byte) (*CookieStore, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("cookie store requires at least one signing key")
+	}
+	for _, key := range keys {
+		if len(key) < 32 {
+			return nil, errors.New("session signing key must be at least 32 bytes")
+		}
+	}
+	return &CookieStore{keys: keys, options: options.withDefaults()}, nil
+}
+
+// Load восстанавливает сессию из cookie запроса, проверяя подпись и срок действия.
+func (st *CookieStore) Load(r *http.Request) (*Session, error) {
+	c, err := r.Cookie(st.options.CookieName)
+	if err != nil {
+		return newSession(st.options.TTL), nil
+	}
+
+	payload, ok := st.decode(c.Value)
+	if !ok || time.Now().Unix() >= payload.ExpiresAt {
+		return newSession(st.options.TTL), nil
+	}
+
+	if payload.Values == nil {
+		payload.Values = map[string]any{}
+	}
+	return &Session{
+		ID:        payload.ID,
+		Values:    payload.Values,
+		ExpiresAt: time.Unix(payload.ExpiresAt, 0),
+	}, nil
+}
+
+// Save подписывает данные сессии и устанавливает cookie в ответе.
+func (st *CookieStore) Save(w http.ResponseWriter, r *http.Request, s *Session) error {
+	req := request.InitRequest(r)
+	if s.destroyed {
+		if !s.isNew {
+			http.SetCookie(w, expiredCookie(req, st.options.CookieName))
+		}
+		return nil
+	}
+	if !s.needsSave(st.options.TTL) {
+		return nil
+	}
+
+	s.ExpiresAt = time.Now().Add(st.options.TTL)
+	value, err := st.encode(cookiePayload{ID: s.ID, Values: s.Values, ExpiresAt: s.ExpiresAt.Unix()})
+	if err != nil {
+		return err
+	}
+	if len(value) > maxCookieSize {
+		return ErrCookieTooLarge
+	}
+
+	http.SetCookie(w, req.NewCookie(st.options.CookieName, value, st.options.TTL))
+	return nil
+}
+
+// encode сериализует данные сессии и добавляет к ним подпись.
+func (st *CookieStore) encode(payload cookiePayload) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	body := base64.RawURLEncoding.EncodeToString(data)
+	return body + "." + base64.RawURLEncoding.EncodeToString(sign(st.keys[0], body)), nil
+}
+
+// decode проверяет подпись значения cookie любым из ключей и разбирает данные сессии.
+func (st *CookieStore) decode(value string) (cookiePayload, bool) {
+	var payload cookiePayload
+	body, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return payload, false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return payload, false
+	}
+
+	valid := false
+	for _, key := range st.keys {
+		if hmac.Equal(mac, sign(key, body)) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return payload, false
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil || json.Unmarshal(data, &payload) != nil {
+		return payload, false
+	}
+	return payload, true
+}
+
+// sign вычисляет HMAC-SHA256 от строки body.
+func sign(key []byte, body string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(body))
+	return mac.Sum(nil)
+}
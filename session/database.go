@@ -0,0 +1,98 @@
+// Package session реализует серверные сессии клиентов поверх cookie.
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"server/models"
+	"server/request"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DatabaseStore хранит данные сессий в базе данных через GORM.
+// В cookie передаётся только идентификатор сессии, а в базе хранится его SHA-256 хеш.
+type DatabaseStore struct {
+	db      *gorm.DB
+	options Options
+}
+
+// NewDatabaseStore создаёт хранилище сессий в базе данных db.
+func NewDatabaseStore(db *gorm.DB, options Options) *DatabaseStore {
+	return &DatabaseStore{db: db, options: options.withDefaults()}
+}
+
+// Load загружает сессию по идентификатору из cookie запроса.
+func (st *DatabaseStore) Load(r *http.Request) (*Session, error) {
+	c, err := r.Cookie(st.options.CookieName)
+	if err != nil || c.Value == "" {
+		return newSession(st.options.TTL), nil
+	}
+
+	record, err := models.GetSessionByID(st.db.WithContext(r.Context()), hashID(c.Value))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return newSession(st.options.TTL), nil
+		}
+		return newSession(st.options.TTL), err
+	}
+
+	values := map[string]any{}
+	if err := json.Unmarshal([]byte(record.Data), &values); err != nil {
+		return newSession(st.options.TTL), err
+	}
+	return &Session{ID: c.Value, Values: values, ExpiresAt: record.ExpiresAt}, nil
+}
+
+// Save сохраняет данные сессии в базе и устанавливает cookie с её идентификатором.
+// При ротации или уничтожении сессии предыдущая запись удаляется.
+func (st *DatabaseStore) Save(w http.ResponseWriter, r *http.Request, s *Session) error {
+	req := request.InitRequest(r)
+	db := st.db.WithContext(r.Context())
+
+	if s.previousID != "" {
+		if err := models.DeleteSession(db, hashID(s.previousID)); err != nil {
+			return err
+		}
+		s.previousID = ""
+	}
+
+	if s.destroyed {
+		if s.isNew {
+			return nil
+		}
+		http.SetCookie(w, expiredCookie(req, st.options.CookieName))
+		return models.DeleteSession(db, hashID(s.ID))
+	}
+	if !s.needsSave(st.options.TTL) {
+		return nil
+	}
+
+	data, err := json.Marshal(s.Values)
+	if err != nil {
+		return err
+	}
+	s.ExpiresAt = time.Now().Add(st.options.TTL)
+	record := &models.Session{ID: hashID(s.ID), Data: string(data), ExpiresAt: s.ExpiresAt}
+	if err := models.SaveSession(db, record); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, req.NewCookie(st.options.CookieName, s.ID, st.options.TTL))
+	return nil
+}
+
+// PurgeExpired удаляет истёкшие сессии и возвращает их количество.
+func (st *DatabaseStore) PurgeExpired() (int64, error) {
+	return models.DeleteExpiredSessions(st.db)
+}
+
+// hashID возвращает SHA-256 хеш идентификатора сессии в шестнадцатеричном виде.
+func hashID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
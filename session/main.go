@@ -0,0 +1,170 @@
+// Package session реализует серверные сессии клиентов поверх cookie.
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"log"
+	"net/http"
+	"server/request"
+	"server/types"
+	"time"
+)
+
+const sessionKey contextKey = "session"
+
+// Get возвращает значение по ключу key и признак его наличия.
+func (s *Session) Get(key string) (any, bool) {
+	val, ok := s.Values[key]
+	return val, ok
+}
+
+// Set сохраняет значение val по ключу key.
+func (s *Session) Set(key string, val any) {
+	s.Values[key] = val
+	s.modified = true
+}
+
+// Delete удаляет значение по ключу key.
+func (s *Session) Delete(key string) {
+	if _, ok := s.Values[key]; ok {
+		delete(s.Values, key)
+		s.modified = true
+	}
+}
+
+// Rotate выдаёт сессии новый идентификатор, сохраняя данные. Следует вызывать
+// при смене уровня привилегий, например после входа пользователя.
+// DatabaseStore удаляет запись со старым идентификатором, и он становится недействительным.
+// CookieStore не хранит состояние на сервере: прежнее значение cookie остаётся
+// действительным до истечения срока сессии, меняется только идентификатор.
+func (s *Session) Rotate() {
+	if s.previousID == "" && !s.isNew {
+		s.previousID = s.ID
+	}
+	s.ID = newID()
+	s.modified = true
+}
+
+// Destroy уничтожает сессию и удаляет её cookie у клиента.
+func (s *Session) Destroy() {
+	s.Values = map[string]any{}
+	s.destroyed = true
+}
+
+// IsNew сообщает, создана ли сессия в текущем запросе.
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+// FromRequest возвращает сессию текущего запроса.
+// Возвращает nil, если middleware сессий не подключён.
+func FromRequest(r *request.Request) *Session {
+	s, _ := r.Req.Context().Value(sessionKey).(*Session)
+	return s
+}
+
+// Middleware загружает сессию из хранилища store перед обработчиком
+// и передаёт её хранилищу на сохранение перед отправкой ответа.
+func Middleware(store Store) types.Middleware {
+	return func(next types.HandlerFunc) types.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			s, err := store.Load(r)
+			if err != nil {
+				log.Printf("session: load failed: %v", err)
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), sessionKey, s))
+			hw := &hookWriter{ResponseWriter: w}
+			hw.before = func() {
+				if err := store.Save(w, r, s); err != nil {
+					log.Printf("session: save failed: %v", err)
+				}
+			}
+
+			next(hw, r)
+			hw.flush()
+		}
+	}
+}
+
+// newSession создаёт пустую сессию с новым идентификатором.
+func newSession(ttl time.Duration) *Session {
+	return &Session{
+		ID:        newID(),
+		Values:    map[string]any{},
+		ExpiresAt: time.Now().Add(ttl),
+		isNew:     true,
+	}
+}
+
+// needsSave определяет, нужно ли сохранять сессию: при изменении, уничтожении
+// или когда прошло больше половины её времени жизни ttl (продление сессии).
+func (s *Session) needsSave(ttl time.Duration) bool {
+	if s.destroyed || s.modified {
+		return true
+	}
+	return !s.isNew && time.Until(s.ExpiresAt) < ttl/2
+}
+
+// withDefaults заполняет незаданные параметры значениями по умолчанию.
+func (o Options) withDefaults() Options {
+	if o.CookieName == "" {
+		o.CookieName = "session"
+	}
+	if o.TTL <= 0 {
+		o.TTL = 24 * time.Hour
+	}
+	return o
+}
+
+// expiredCookie возвращает cookie с истёкшим сроком действия для удаления сессии у клиента.
+func expiredCookie(req *request.Request, name string) *http.Cookie {
+	c := req.NewCookie(name, "", 0)
+	c.MaxAge = -1
+	c.Expires = time.Unix(0, 0)
+	return c
+}
+
+// newID генерирует криптографически случайный идентификатор сессии.
+func newID() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// hookWriter вызывает функцию before непосредственно перед первой записью ответа,
+// чтобы успеть установить заголовки, например Set-Cookie.
+type hookWriter struct {
+	http.ResponseWriter
+	before func()
+	done   bool
+}
+
+// flush вызывает before, если ответ так и не был записан.
+func (w *hookWriter) flush() {
+	if !w.done {
+		w.done = true
+		w.before()
+	}
+}
+
+// WriteHeader вызывает before и передаёт код ответа дальше.
+func (w *hookWriter) WriteHeader(code int) {
+	w.flush()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write вызывает before и передаёт тело ответа дальше.
+func (w *hookWriter) Write(b []byte) (int, error) {
+	w.flush()
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap возвращает исходный http.ResponseWriter.
+func (w *hookWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,38 @@
+// Package session реализует серверные сессии клиентов поверх cookie.
+package session
+
+import (
+	"net/http"
+	"time"
+)
+
+// contextKey используется для хранения сессии в контексте HTTP-запроса.
+type contextKey string
+
+// Store описывает хранилище сессий.
+type Store interface {
+	// Load возвращает сессию текущего запроса или новую пустую сессию,
+	// если cookie отсутствует, повреждена или сессия истекла.
+	Load(r *http.Request) (*Session, error)
+	// Save сохраняет сессию и устанавливает cookie в ответе.
+	// Уничтоженная сессия удаляется вместе с cookie.
+	Save(w http.ResponseWriter, r *http.Request, s *Session) error
+}
+
+// Options задаёт общие параметры хранилищ сессий.
+type Options struct {
+	CookieName string        // Имя cookie, "session" по умолчанию
+	TTL        time.Duration // Время жизни сессии, 24 часа по умолчанию
+}
+
+// Session содержит данные одной клиентской сессии.
+type Session struct {
+	ID        string         // Идентификатор сессии
+	Values    map[string]any // Данные сессии, сериализуемые в JSON
+	ExpiresAt time.Time      // Время истечения сессии
+
+	previousID string // идентификатор до ротации, который нужно инвалидировать
+	isNew      bool   // сессия создана в текущем запросе
+	modified   bool   // данные изменены и должны быть сохранены
+	destroyed  bool   // сессия уничтожена и должна быть удалена
+}
@@ -8,11 +8,19 @@ import (
 // HandlerFunc определяет тип функции-обработчика HTTP-запросов.
 type HandlerFunc func(w http.ResponseWriter, r *http.Request)
 
+// Middleware оборачивает обработчик HTTP-запросов дополнительной логикой,
+// выполняемой до и/или после него.
+type Middleware func(next HandlerFunc) HandlerFunc
+
 // JsonResponse представляет структуру JSON-ответа API.
 type JsonResponse struct {
 	Status  string `json:"status"`            // Статус ответа, например "success" или "error"
 	Message string `json:"message,omitempty"` // Сообщение об ошибке или дополнительная информация
 	Data    any    `json:"data,omitempty"`    // Данные ответа (может быть любого типа)
+
+	RequestID string      `json:"request_id,omitempty"` // Идентификатор запроса, добавляется к ответам с ошибкой
+	Code      int         `json:"-"`                    // HTTP-код ответа, 200 по умолчанию
+	Header    http.Header `json:"-"`                    // Дополнительные заголовки ответа
 }
 
 // JsonHandlerFunc определяет тип функции-обработчика, которая принимает